@@ -4,9 +4,16 @@ import (
 	"encoding/hex"
 	"flag"
 	"fmt"
+	"github.com/quanterall/kitchensink/pkg/based32"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/crockford"
 	"github.com/quanterall/kitchensink/pkg/grpc/client"
 	"github.com/quanterall/kitchensink/pkg/proto"
+	"github.com/quanterall/kitchensink/pkg/qr"
+	"github.com/quanterall/kitchensink/pkg/radix"
+	"github.com/quanterall/kitchensink/pkg/zbase32"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -23,10 +30,55 @@ var (
 	)
 	decode = flag.String(
 		"d", "",
-		"based32 encoded string to convert back to hex",
+		"based32 encoded string to convert back to hex, spaces, hyphens "+
+			"and line breaks between groups of characters are ignored",
+	)
+	codecName = flag.String(
+		"c", "based32",
+		"the codec the server was started with, one of based32, base58, "+
+			"base36, base64url, crockford or zbase32",
+	)
+	group = flag.Int(
+		"g", 0,
+		"split the encoded output into groups of this many characters "+
+			"separated by hyphens, for codecs that ignore them when decoding",
+	)
+	showQR = flag.Bool(
+		"q", false,
+		"also print the encoded output as a QR code that can be scanned "+
+			"from the terminal",
+	)
+	lightTerminal = flag.Bool(
+		"l", false,
+		"the terminal shows dark text on a light background, for printing "+
+			"QR codes the right way round",
 	)
 )
 
+// served are the codecs that the server can be started with, by the names
+// given to the -c flag of basedd, and whether their decoders ignore hyphens
+// between groups of characters, so that the output can be grouped. The
+// base64url alphabet has the hyphen in it, and z-base-32 has no separators.
+var served = map[string]struct {
+	cdc      *codec.Codec
+	grouping bool
+}{
+	"based32":   {based32.Codec, true},
+	"base58":    {radix.Base58, true},
+	"base36":    {radix.Base36, true},
+	"base64url": {radix.Base64URL, false},
+	"crockford": {crockford.CrockfordCheck, true},
+	"zbase32":   {zbase32.Codec, false},
+}
+
+// singleCase returns true if the letters of the charset are all of one case,
+// as the codecs with such charsets decode strings in either case.
+func singleCase(charset string) bool {
+
+	return strings.ToLower(charset) == charset ||
+		strings.ToUpper(charset) == charset
+}
+
 func main() {
 
 	flag.Parse()
@@ -60,6 +112,20 @@ func main() {
 
 	}
 
+	srv, ok := served[*codecName]
+	if !ok {
+
+		_, _ = fmt.Fprintf(os.Stderr, "Unknown codec '%s'\n", *codecName)
+		os.Exit(1)
+	}
+	if *group > 0 && !srv.grouping {
+
+		_, _ = fmt.Fprintf(
+			os.Stderr, "The %s codec can't be grouped\n", *codecName,
+		)
+		os.Exit(1)
+	}
+
 	// Create a new client
 	cli, err := client.New(defaultAddr, 5*time.Second)
 	if err != nil {
@@ -97,7 +163,30 @@ func main() {
 			},
 		)
 
-		fmt.Println(encRes.GetEncodedString())
+		// Grouping is only for display so it is done here rather than by the
+		// server, where the Human Readable Part of the codec it serves is.
+		fmt.Println(
+			based32.Group(srv.cdc, encRes.GetEncodedString(), *group, "-"),
+		)
+
+		// Upper case fits in the more compact alphanumeric mode of QR codes,
+		// but only codecs with single case charsets decode either case, so
+		// the others are left as they are and encoded in byte mode.
+		if *showQR && encRes.GetEncodedString() != "" {
+
+			encoded := encRes.GetEncodedString()
+			if singleCase(srv.cdc.Charset) {
+
+				encoded = strings.ToUpper(encoded)
+			}
+			code, err := qr.Encode(encoded, qr.Medium)
+			if err != nil {
+
+				_, _ = fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			fmt.Print(code.Terminal(!*lightTerminal))
+		}
 
 	} else if *decode != "" {
 
@@ -4,7 +4,12 @@ import (
 	"flag"
 	"fmt"
 	"github.com/cybriq/interrupt"
+	"github.com/quanterall/kitchensink/pkg/based32"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/crockford"
 	"github.com/quanterall/kitchensink/pkg/grpc/server"
+	"github.com/quanterall/kitchensink/pkg/radix"
+	"github.com/quanterall/kitchensink/pkg/zbase32"
 	"net"
 	"os"
 )
@@ -16,6 +21,26 @@ var serverAddr = flag.String("a", defaultAddr,
 		"- omit host to bind to all network interfaces",
 )
 
+var codecName = flag.String("c", "based32",
+	"The codec to serve, one of based32, base58, base36, base64url, "+
+		"crockford or zbase32",
+)
+
+var compress = flag.Bool("z", false,
+	"Compress payloads with DEFLATE when that makes them shorter, which is "+
+		"only possible with the based32 codec",
+)
+
+// codecs are the codecs that can be chosen with the -c flag.
+var codecs = map[string]*codec.Codec{
+	"based32":   based32.Codec,
+	"base58":    radix.Base58,
+	"base36":    radix.Base36,
+	"base64url": radix.Base64URL,
+	"crockford": crockford.CrockfordCheck,
+	"zbase32":   zbase32.Codec,
+}
+
 var killAll = make(chan struct{})
 
 func main() {
@@ -41,7 +66,35 @@ func main() {
 		os.Exit(1)
 	}
 
-	svc := server.New(addr, 8)
+	cdc, ok := codecs[*codecName]
+	if !ok {
+
+		log.Printf("Unknown codec '%s'", *codecName)
+		os.Exit(1)
+	}
+
+	// Every based32 codec decodes compressed strings, so only the encoder
+	// needs a codec of its own to compress them.
+	if *compress {
+
+		if cdc != based32.Codec {
+
+			log.Printf("The %s codec can't compress", cdc.Name)
+			os.Exit(1)
+		}
+
+		cdc, err = based32.NewCodec(cdc.Name, cdc.Charset, cdc.HRP,
+			based32.WithVersion(based32.Version1), based32.WithCompression(),
+		)
+		if err != nil {
+
+			log.Println(err)
+			os.Exit(1)
+		}
+	}
+	log.Printf("serving the %s codec", cdc.Name)
+
+	svc := server.NewWithCodec(addr, 8, cdc)
 
 	// interrupt is a library that allows the proper handling of OS interrupt
 	// signals to allow a clean shutdown and ensure such things as databases are
@@ -0,0 +1,413 @@
+// Command basedvec generates the test vectors for the based32 format, which are
+// used to check that other implementations, such as ports to other languages,
+// produce and accept exactly the same strings as the based32 package.
+//
+// The vectors are generated from based32.Codec, so they are regenerated with
+// this command whenever the format changes on purpose, and the tests of the
+// based32 package check the package against them. From the repository root:
+//
+//	go run ./cmd/basedvec -o pkg/based32/testdata/vectors.json
+//
+// The vectors are a JSON object with the following fields:
+//
+//   - codec, hrp, charset: the name, Human Readable Part and charset of the
+//     codec the vectors are for.
+//   - valid: strings that must decode to the bytes given in hex. Unless
+//     decodeOnly is true, encoding the bytes in the given format version must
+//     also produce exactly the string.
+//   - invalid: strings that must fail to decode, with the name of the error
+//     from the proto.Error enum that must be returned.
+package main
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/based32"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"lukechampine.com/blake3"
+	"os"
+	"strings"
+)
+
+var output = flag.String(
+	"o", "",
+	"file to write the vectors to, instead of the standard output",
+)
+
+// Vectors is the layout of the test vector file.
+type Vectors struct {
+	Comment string    `json:"comment"`
+	Codec   string    `json:"codec"`
+	HRP     string    `json:"hrp"`
+	Charset string    `json:"charset"`
+	Valid   []Valid   `json:"valid"`
+	Invalid []Invalid `json:"invalid"`
+}
+
+// Valid is a string that must decode to the bytes in Hex.
+type Valid struct {
+	Description string `json:"description"`
+	Version     int    `json:"version"`
+	Hex         string `json:"hex"`
+	Encoded     string `json:"encoded"`
+	DecodeOnly  bool   `json:"decodeOnly,omitempty"`
+}
+
+// Invalid is a string that must fail to decode with the named error.
+type Invalid struct {
+	Description string `json:"description"`
+	Encoded     string `json:"encoded"`
+	Error       string `json:"error"`
+}
+
+const comment = "Generated by cmd/basedvec from based32.Codec. Do not edit."
+
+func main() {
+
+	flag.Parse()
+
+	vectors, err := generate()
+	if err != nil {
+
+		_, _ = fmt.Fprintln(os.Stderr, "basedvec:", err)
+		os.Exit(1)
+	}
+
+	out, err := json.MarshalIndent(vectors, "", "  ")
+	if err != nil {
+
+		_, _ = fmt.Fprintln(os.Stderr, "basedvec:", err)
+		os.Exit(1)
+	}
+	out = append(out, '\n')
+
+	if *output == "" {
+
+		_, _ = os.Stdout.Write(out)
+		return
+	}
+
+	if err = os.WriteFile(*output, out, 0644); err != nil {
+
+		_, _ = fmt.Fprintln(os.Stderr, "basedvec:", err)
+		os.Exit(1)
+	}
+}
+
+// generate creates the vectors, checking each one against based32.Codec.
+func generate() (vectors Vectors, err error) {
+
+	cdc := based32.Codec
+	vectors = Vectors{
+		Comment: comment,
+		Codec:   cdc.Name,
+		HRP:     cdc.HRP,
+		Charset: cdc.Charset,
+	}
+
+	v1, err := based32.NewCodec(
+		cdc.Name, cdc.Charset, cdc.HRP, based32.WithVersion(based32.Version1),
+	)
+	if err != nil {
+		return
+	}
+
+	// Every length up to 40 bytes covers every check length several times
+	// over, and the common hash lengths are added to those.
+	var lengths []int
+	for l := 1; l <= 40; l++ {
+
+		lengths = append(lengths, l)
+	}
+	lengths = append(lengths, 48, 64)
+
+	inputs := makeInputs(lengths)
+
+	for version, c := range []*codec.Codec{cdc, v1} {
+
+		for _, input := range inputs {
+
+			if err = vectors.addValid(c, version,
+				fmt.Sprintf("%d bytes", len(input)), input,
+			); err != nil {
+				return
+			}
+		}
+
+		// The inputs where all of the bits are the same are the edges of the
+		// ranges of the encoded characters.
+		for _, l := range []int{1, 20, 32} {
+
+			for _, b := range []byte{0, 0xff} {
+
+				input := []byte(strings.Repeat(string([]byte{b}), l))
+				if err = vectors.addValid(c, version,
+					fmt.Sprintf("%d bytes of %02x", l, b), input,
+				); err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	// Decoders must accept the alternative forms of a string as well.
+	input := inputs[19]
+	encoded, err := cdc.Encode(input)
+	if err != nil {
+		return
+	}
+	data := encoded[len(cdc.HRP):]
+
+	for _, alt := range []Valid{
+		{
+			Description: "upper case",
+			Encoded:     strings.ToUpper(encoded),
+		},
+		{
+			Description: "lower case",
+			Encoded:     strings.ToLower(encoded),
+		},
+		{
+			Description: "grouped with hyphens",
+			Encoded:     based32.Group(cdc, encoded, 4, "-"),
+		},
+		{
+			Description: "grouped with spaces and a line break",
+			Encoded: cdc.HRP + " " + data[:8] + " " + data[8:16] + "\n" +
+				data[16:],
+		},
+	} {
+
+		alt.Hex = hex.EncodeToString(input)
+		alt.DecodeOnly = true
+		if err = vectors.add(alt); err != nil {
+			return
+		}
+	}
+
+	return vectors, vectors.addInvalids(cdc, inputs[19])
+}
+
+// makeInputs creates deterministic inputs of the given lengths from a chain of
+// hashes, each hash being of the one before.
+func makeInputs(lengths []int) (inputs [][]byte) {
+
+	hash := blake3.Sum256([]byte("basedvec"))
+
+	for _, l := range lengths {
+
+		input := make([]byte, 0, l)
+		for len(input) < l {
+
+			hash = blake3.Sum256(hash[:])
+			input = append(input, hash[:]...)
+		}
+
+		inputs = append(inputs, input[:l])
+	}
+
+	return
+}
+
+// addValid encodes the input with the codec and adds it as a valid vector.
+func (v *Vectors) addValid(cdc *codec.Codec, version int, description string,
+	input []byte,
+) (err error) {
+
+	var encoded string
+	if encoded, err = cdc.Encode(input); err != nil {
+		return
+	}
+
+	return v.add(Valid{
+		Description: description,
+		Version:     version,
+		Hex:         hex.EncodeToString(input),
+		Encoded:     encoded,
+	})
+}
+
+// add adds a valid vector after checking that it decodes correctly.
+func (v *Vectors) add(valid Valid) (err error) {
+
+	decoded, err := based32.Codec.Decode(valid.Encoded)
+	if err != nil {
+
+		return fmt.Errorf("%s: '%s': %v", valid.Description, valid.Encoded, err)
+	}
+
+	if hex.EncodeToString(decoded) != valid.Hex {
+
+		return fmt.Errorf(
+			"%s: '%s' decoded to %x", valid.Description, valid.Encoded, decoded,
+		)
+	}
+
+	v.Valid = append(v.Valid, valid)
+
+	return
+}
+
+// addInvalids adds the invalid vectors, made from the encoding of the input
+// and from raw bytes with particular headers and checks.
+func (v *Vectors) addInvalids(cdc *codec.Codec, input []byte) (err error) {
+
+	encoded, err := cdc.Encode(input)
+	if err != nil {
+		return
+	}
+	hrp, data := encoded[:len(cdc.HRP)], encoded[len(cdc.HRP):]
+	enc := base32.NewEncoding(cdc.Charset)
+
+	// raw encodes the bytes as they are, leaving off the first character if
+	// short is true, as version 0 does.
+	raw := func(b []byte, short bool) string {
+
+		s := enc.EncodeToString(b)
+		if short {
+
+			s = s[1:]
+		}
+
+		return hrp + s
+	}
+
+	// checked appends the check of the given length to the header and
+	// payload, covering the header too if cover is true.
+	checked := func(header byte, payload []byte, checkLen int, cover bool,
+	) []byte {
+
+		b := append([]byte{header}, payload...)
+		covered := b[1:]
+		if cover {
+
+			covered = b
+		}
+		sum := blake3.Sum256(covered)
+
+		return append(b, sum[:checkLen]...)
+	}
+
+	// A different character in the data part, and two swapped.
+	substituted := []byte(data)
+	substituted[5] = cdc.Charset[(strings.IndexByte(cdc.Charset, data[5])+1)%32]
+	swapped := []byte(data)
+	swapped[5], swapped[6] = swapped[6], swapped[5]
+	if swapped[5] == swapped[6] {
+
+		return errors.New("choose an input without repeated characters")
+	}
+
+	// A character not in the charset, which for the default charset is one of
+	// the letters that are left out as they look like others.
+	notInCharset := byte('b')
+	if strings.IndexByte(cdc.Charset, notInCharset) >= 0 {
+
+		return errors.New("choose a character not in the charset")
+	}
+
+	// Changing the version 1 header to a different check length.
+	v1 := checked(byte(based32.Version1<<3|2), input[:16], 3, true)
+	v1[0] = byte(based32.Version1<<3 | 1)
+
+	for _, invalid := range []Invalid{
+		{
+			Description: "empty string",
+			Encoded:     "",
+			Error:       proto.Error_INCORRECT_HUMAN_READABLE_PART.String(),
+		},
+		{
+			Description: "only the human readable part",
+			Encoded:     hrp,
+			Error:       proto.Error_ZERO_LENGTH.String(),
+		},
+		{
+			Description: "wrong human readable part",
+			Encoded:     hrp[:len(hrp)-1] + "X" + data,
+			Error:       proto.Error_INCORRECT_HUMAN_READABLE_PART.String(),
+		},
+		{
+			Description: "missing human readable part",
+			Encoded:     data,
+			Error:       proto.Error_INCORRECT_HUMAN_READABLE_PART.String(),
+		},
+		{
+			Description: "substituted character",
+			Encoded:     hrp + string(substituted),
+			Error:       proto.Error_CHECK_FAILED.String(),
+		},
+		{
+			Description: "swapped characters",
+			Encoded:     hrp + string(swapped),
+			Error:       proto.Error_CHECK_FAILED.String(),
+		},
+		{
+			Description: "mixed case",
+			Encoded:     hrp + strings.ToUpper(data[:1]) + data[1:],
+			Error:       proto.Error_MIXED_CASE.String(),
+		},
+		{
+			Description: "character not in the charset",
+			Encoded:     hrp + data[:5] + string(notInCharset) + data[6:],
+			Error:       proto.Error_INVALID_CHARACTER.String(),
+		},
+		{
+			Description: "missing character",
+			Encoded:     hrp + data[:5] + data[6:],
+			Error:       proto.Error_INVALID_LENGTH.String(),
+		},
+		{
+			Description: "two extra characters",
+			Encoded:     hrp + data[:5] + data[5:7] + data[5:],
+			Error:       proto.Error_INVALID_LENGTH.String(),
+		},
+		{
+			Description: "version 0 header in a full length string",
+			Encoded:     raw(checked(6, input[:3], 6, false), false),
+			Error:       proto.Error_INVALID_LENGTH.String(),
+		},
+		{
+			Description: "check length longer than the data",
+			Encoded:     raw([]byte{6, 1, 2, 3, 4}, true),
+			Error:       proto.Error_CHECK_TOO_SHORT.String(),
+		},
+		{
+			Description: "check length of zero",
+			Encoded:     raw([]byte{0, 1, 2, 3, 4}, true),
+			Error:       proto.Error_CHECK_TOO_SHORT.String(),
+		},
+		{
+			Description: "changed check length in a version 1 header",
+			Encoded:     raw(v1, false),
+			Error:       proto.Error_CHECK_FAILED.String(),
+		},
+		{
+			Description: "unsupported version",
+			Encoded: raw(checked(
+				byte((based32.LatestVersion+1)<<3|2), input[:16], 3, true,
+			), false),
+			Error: proto.Error_UNSUPPORTED_VERSION.String(),
+		},
+	} {
+
+		// The vectors are only useful if the codec agrees with them.
+		_, err = based32.Codec.Decode(invalid.Encoded)
+		var e proto.Error
+		if !errors.As(err, &e) || e.String() != invalid.Error {
+
+			return fmt.Errorf(
+				"%s: '%s' expected error %s got %v",
+				invalid.Description, invalid.Encoded, invalid.Error, err,
+			)
+		}
+
+		v.Invalid = append(v.Invalid, invalid)
+	}
+
+	return nil
+}
@@ -12,10 +12,10 @@
 package based32
 
 import (
+	"bytes"
 	"encoding/base32"
 	"github.com/quanterall/kitchensink/pkg/codec"
 	"github.com/quanterall/kitchensink/pkg/proto"
-	"lukechampine.com/blake3"
 	"strings"
 )
 
@@ -24,6 +24,10 @@ import (
 // value of the character.
 const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
 
+// maxCheckLen is the largest check length that any of the check algorithms can
+// produce, which is the length of the hashes.
+const maxCheckLen = 32
+
 // Codec provides the encoder/decoder implementation created by makeCodec.
 //
 // This variable is sometimes called a "Singleton" in other languages, and in Go
@@ -43,6 +47,7 @@ var Codec = makeCodec(
 	"Base32Check",
 	charset,
 	"QNTRL",
+	defaultOptions(),
 )
 
 func getCheckLen(length int) (checkLen int) {
@@ -87,10 +92,183 @@ func getCheckLen(length int) (checkLen int) {
 	return checkLen
 }
 
-// getCutPoint is made into a function because it is needed more than once.
+// getCutPoint is made into a function because it is needed more than once. It
+// returns the index of the first check byte in the decoded bytes.
 func getCutPoint(length, checkLen int) int {
 
-	return length - checkLen - 1
+	return length - checkLen
+}
+
+// grow returns b with room for at least n more bytes after its length, which
+// is left as it is. Like the built in append, when there is not enough room it
+// copies b to a new, larger buffer.
+func grow(b []byte, n int) []byte {
+
+	if cap(b)-len(b) >= n {
+
+		return b
+	}
+
+	grown := make([]byte, len(b), len(b)+n)
+	copy(grown, b)
+
+	return grown
+}
+
+// appendRaw appends the bytes that are encoded for the input to dst: the
+// header, the input, and the check. This layout is shared by all of the
+// encodings of the package, so the same data has the same check whichever way
+// it is written. The input is what compress returned, and compressed says
+// whether it was compressed, for the header.
+func appendRaw(dst, input []byte, compressed bool, o options) (raw []byte) {
+
+	checkLen := o.checkLen(len(input))
+	start := len(dst)
+	raw = grow(dst, len(input)+checkLen+1)[:start+len(input)+checkLen+1]
+
+	// Add the check length byte to the front, which from version 1 also
+	// contains the version.
+	raw[start] = makeHeader(o.version, checkLen, compressed)
+
+	// Then copy the input bytes for beginning segment.
+	copy(raw[start+1:], input)
+
+	// Then copy the check to the end of the input. Version 0 only covers the
+	// input with the check, later versions cover the header as well.
+	checked := raw[start+1 : start+len(input)+1]
+	if o.version > Version0 {
+
+		checked = raw[start : start+len(input)+1]
+	}
+	sum := o.check(checked)
+	copy(raw[start+len(input)+1:], sum[:checkLen])
+
+	return
+}
+
+// invalidChar marks the characters that are not in the charset in the table
+// of character values.
+const invalidChar = 0xff
+
+// decode decodes text, which must be a multiple of 8 characters long, into
+// dst, which must be 5/8 of the length of text, using the table of character
+// values of the charset.
+//
+// This does the same as the standard library base32 Decode for unpadded input,
+// which makes a copy of its input each time it is called, so here it is done
+// directly instead to avoid the allocation. The error for a character not in
+// the charset is the same base32.CorruptInputError as it gives.
+func decode(dst, text []byte, values *[256]byte) (n int, err error) {
+
+	for i := 0; i < len(text); i += 8 {
+
+		// Each group of 8 characters is 40 bits, which is 5 bytes.
+		var bits uint64
+		for j := i; j < i+8; j++ {
+
+			v := values[text[j]]
+			if v == invalidChar {
+
+				return n, base32.CorruptInputError(j)
+			}
+			bits = bits<<5 | uint64(v)
+		}
+
+		for j := 4; j >= 0; j-- {
+
+			dst[n+j] = byte(bits)
+			bits >>= 8
+		}
+		n += 5
+	}
+
+	return
+}
+
+// verify checks the raw bytes of an encoding, the header, the data and the
+// check, with the check algorithm and minimum check length of the options, as
+// used by the Check of the codecs.
+func verify(input []byte, o options) (err error) {
+
+	// We must do this check or the next statement will cause a bounds check
+	// panic. Note that zero length and nil slices are different, but have
+	// the same effect in this case, so both must be checked.
+	switch {
+	case len(input) < 1:
+
+		err = proto.Error_ZERO_LENGTH
+		return
+
+	case input == nil:
+
+		err = proto.Error_NIL_SLICE
+		return
+	}
+
+	// The check length is encoded into the first byte in order to ensure
+	// the data is cut correctly to perform the integrity check, along with
+	// the version in versions after 0.
+	version, checkLen, compressed := parseHeader(input[0])
+	if err = checkVersion(version, compressed); err != nil {
+		return
+	}
+
+	// Ensure there is at enough bytes in the input to run a check on, and
+	// that there is a check at all, as a check length of zero would pass
+	// anything.
+	//
+	// A check shorter than the minimum of the codec is too weak to be trusted
+	// even if it is correct.
+	if checkLen < 1 || checkLen < o.minCheckLen || len(input) < checkLen+1 {
+
+		err = proto.Error_CHECK_TOO_SHORT
+		return
+	}
+
+	// A corrupted length byte can ask for a longer check than the hash
+	// functions can produce, which would panic when the hash is sliced.
+	if checkLen > maxCheckLen {
+
+		err = proto.Error_CHECK_FAILED
+		return
+	}
+
+	// Find the index to cut the input to find the checksum value. We need
+	// this same value twice so it must be made into a variable.
+	cutPoint := getCutPoint(len(input), checkLen)
+
+	// Here is an example of a multiple assignment and more use of the
+	// slicing operator.
+	payload, checksum := input[1:cutPoint], input[cutPoint:]
+
+	// After version 0 the check also covers the header.
+	if version > Version0 {
+
+		payload = input[:cutPoint]
+	}
+
+	// A checksum is checked in all cases by taking the data received, and
+	// applying the checksum generation function, and then comparing the
+	// checksum to the one attached to the received data with checksum
+	// present.
+	//
+	// Note: The check functions return an array rather than a slice, so
+	// the computed check stays on the stack and checking allocates
+	// nothing. Slicing it gives a []byte to compare with bytes.Equal, as
+	// unlike strings and arrays, slices can't be compared with ==.
+	computedChecksum := o.check(payload)
+
+	// Here we assign to the return variable the result of the comparison.
+	// by doing this instead of using an if and returns, the meaning of the
+	// comparison is more clear by the use of the return value's name.
+	valid := bytes.Equal(checksum, computedChecksum[:checkLen])
+
+	if !valid {
+
+		err = proto.Error_CHECK_FAILED
+	}
+
+	return
 }
 
 // makeCodec generates our custom codec as above, into the exported Codec
@@ -104,31 +282,26 @@ func makeCodec(
 	name string,
 	cs string,
 	hrp string,
+	o options,
 ) (cdc *codec.Codec) {
 
 	// Create the codec.Codec struct and put its pointer in the return variable.
 	cdc = &codec.Codec{
-		Name:    name,
-		Charset: cs,
-		HRP:     hrp,
+		Name:      name,
+		Charset:   cs,
+		HRP:       hrp,
+		CheckName: o.checksum.String(),
 	}
 
-	// We need to create the check creation functions first
-	cdc.MakeCheck = func(input []byte, checkLen int) (output []byte) {
-
-		// We use the Blake3 256 bit hash because it is nearly as fast as CRC32
-		// but less complicated to use due to the 32 bit integer conversions to
-		// bytes required to use the CRC32 algorithm.
-		checkArray := blake3.Sum256(input)
-
-		// This truncates the blake3 hash to the prescribed check length
-		return checkArray[:checkLen]
-	}
+	// We need to create the check creation functions first. The available
+	// algorithms are in checksum.go, and the one to use is chosen by the
+	// options.
+	cdc.MakeCheck = makeCheck(o.check)
 
 	// Create a base32.Encoding from the provided charset.
 	enc := base32.NewEncoding(cdc.Charset)
 
-	cdc.Encoder = func(input []byte) (output string, err error) {
+	cdc.AppendEncoder = func(dst, input []byte) (output []byte, err error) {
 
 		if len(input) < 1 {
 
@@ -139,184 +312,363 @@ func makeCodec(
 			//
 			// You can see the error in ../proto/based32.pb.go which is what is
 			// generated by protoc-gen-go.
-			err = proto.Error_ZERO_LENGTH
-			return
+			return dst, proto.Error_ZERO_LENGTH
 		}
 
+		// From here on the input is the data that is encoded, which is
+		// compressed if the codec compresses and that makes it smaller.
+		input, compressed := o.compress(input)
+
 		// The check length depends on the modulus of the length of the data is
 		// order to avoid padding.
-		checkLen := getCheckLen(len(input))
-
-		// The output is longer than the input, so we create a new buffer.
-		outputBytes := make([]byte, len(input)+checkLen+1)
-
-		// Add the check length byte to the front
-		outputBytes[0] = byte(checkLen)
-
-		// Then copy the input bytes for beginning segment.
-		copy(outputBytes[1:len(input)+1], input)
-
-		// Then copy the check to the end of the input.
-		copy(outputBytes[len(input)+1:], cdc.MakeCheck(input, checkLen))
+		checkLen := o.checkLen(len(input))
+
+		// The raw bytes are the header, the input and the check, and the
+		// encoding of them is never padded, so its length is simple to work
+		// out.
+		rawLen := len(input) + checkLen + 1
+		encLen := enc.EncodedLen(rawLen)
+
+		// Everything is built inside dst, so that given enough capacity there
+		// is nothing to allocate. The raw bytes are assembled after the space
+		// for the encoding, and once they have been encoded, that space is
+		// free for the group separators, if any.
+		start := len(dst)
+		extra := rawLen
+		if o.groupSize > 0 {
+
+			seps := groupSeparators(encLen, cdc.HRP != "", o.groupSize) *
+				len(o.groupSeparator)
+			if seps > extra {
+
+				extra = seps
+			}
+		}
+		output = grow(dst, len(cdc.HRP)+encLen+extra)
+		buf := appendRaw(
+			output[:start+len(cdc.HRP)+encLen], input, compressed, o,
+		)
+		copy(buf[start:], cdc.HRP)
+		data := buf[start+len(cdc.HRP) : start+len(cdc.HRP)+encLen]
+		raw := buf[start+len(cdc.HRP)+encLen:]
 
 		// Create the encoding for the output.
-		outputString := enc.EncodeToString(outputBytes)
+		enc.Encode(data, raw)
 
 		// We can omit the first character of the encoding because the length
 		// prefix never uses the first 5 bits of the first byte, and add it back
-		// for the decoder later.
-		trimmedString := outputString[1:]
+		// for the decoder later. This is only true of version 0, as later
+		// versions put the version in these bits.
+		if o.version == Version0 {
 
-		// Prefix the output with the Human Readable Part and append the
-		// encoded string version of the provided bytes.
-		output = cdc.HRP + trimmedString
+			copy(data, data[1:])
+			encLen--
+		}
 
-		return
-	}
+		// The output is the Human Readable Part followed by the encoded bytes.
+		output = buf[:start+len(cdc.HRP)+encLen]
 
-	cdc.Check = func(input []byte) (err error) {
+		// Upper case output is for QR codes, which can encode upper case
+		// letters and digits much more compactly than other characters.
+		if o.uppercase {
 
-		// We must do this check or the next statement will cause a bounds check
-		// panic. Note that zero length and nil slices are different, but have
-		// the same effect in this case, so both must be checked.
-		switch {
-		case len(input) < 1:
+			for i := start; i < len(output); i++ {
 
-			err = proto.Error_ZERO_LENGTH
-			return
+				if c := output[i]; c >= 'a' && c <= 'z' {
 
-		case input == nil:
+					output[i] = c - 'a' + 'A'
+				}
+			}
+		}
 
-			err = proto.Error_NIL_SLICE
-			return
+		// If a display grouping has been configured, split the data part into
+		// groups.
+		if o.groupSize > 0 {
+
+			output = groupInPlace(
+				output, start+len(cdc.HRP), cdc.HRP != "",
+				o.groupSize, o.groupSeparator,
+			)
 		}
 
-		// The check length is encoded into the first byte in order to ensure
-		// the data is cut correctly to perform the integrity check.
-		checkLen := int(input[0])
+		return
+	}
 
-		// Ensure there is at enough bytes in the input to run a check on
-		if len(input) < checkLen+1 {
+	// The string form of the encoder is the append form with a new buffer.
+	cdc.Encoder = func(input []byte) (output string, err error) {
 
-			err = proto.Error_CHECK_TOO_SHORT
+		var b []byte
+		if b, err = cdc.AppendEncoder(nil, input); err != nil {
 			return
 		}
 
-		// Find the index to cut the input to find the checksum value. We need
-		// this same value twice so it must be made into a variable.
-		cutPoint := getCutPoint(len(input), checkLen)
+		return string(b), nil
+	}
 
-		// Here is an example of a multiple assignment and more use of the
-		// slicing operator.
-		payload, checksum := input[1:cutPoint], string(input[cutPoint:])
+	cdc.Check = func(input []byte) (err error) {
 
-		// A checksum is checked in all cases by taking the data received, and
-		// applying the checksum generation function, and then comparing the
-		// checksum to the one attached to the received data with checksum
-		// present.
-		//
-		// Note: The casting to string above and here. This makes a copy to the
-		// immutable string, which is not optimal for large byte slices, but for
-		// this short check value, it is a cheap operation on the stack, and an
-		// illustration of the interchangeability of []byte and string, with the
-		// distinction of the availability of a comparison operator for the
-		// string that isn't present for []byte, so for such cases this
-		// conversion is a shortcut method to compare byte slices.
-		computedChecksum := string(cdc.MakeCheck(payload, checkLen))
-
-		// Here we assign to the return variable the result of the comparison.
-		// by doing this instead of using an if and returns, the meaning of the
-		// comparison is more clear by the use of the return value's name.
-		valid := checksum != computedChecksum
-
-		if !valid {
-
-			err = proto.Error_CHECK_FAILED
-		}
+		return verify(input, o)
+	}
 
-		return
+	// The separators ignored by the decoder are the default ones plus the one
+	// used for grouping, if any, except those in the charset, which are data.
+	// They are put into a table so the decoder can look them up quickly.
+	var isSeparator [256]bool
+	separators := DefaultSeparators + o.groupSeparator
+	for i := 0; i < len(separators); i++ {
+
+		isSeparator[separators[i]] = strings.IndexByte(cs, separators[i]) < 0
 	}
 
-	cdc.Decoder = func(input string) (output []byte, err error) {
+	// Charsets of one case accept a data part in either case, which is changed
+	// to the case of the charset as it is decoded.
+	fold := caseFolder(cs) != nil
+	foldLower := fold && strings.ToLower(cs) == cs
+
+	// The decoder looks up the values of the characters in a table, in which
+	// characters not in the charset are marked as invalid.
+	var values [256]byte
+	for i := range values {
+
+		values[i] = invalidChar
+	}
+	for i := 0; i < len(cs); i++ {
+
+		values[cs[i]] = byte(i)
+	}
+
+	cdc.AppendDecoder = func(dst, input []byte) (output []byte, err error) {
+
+		// Strict mode compares the whole of the input with the encoding.
+		original := input
 
 		// Other than for human identification, the HRP is also a validity
 		// check, so if the string prefix is wrong, the entire value is wrong
 		// and won't decode as it is expected.
-		if !strings.HasPrefix(input, cdc.HRP) {
+		if offset := hrpMismatch(cdc.HRP, input, fold); offset >= 0 {
 
-			log.Printf(
-				"Provided string has incorrect human readable part:"+
-					"found '%s' expected '%s'", input[:len(cdc.HRP)], cdc.HRP,
-			)
+			found := input
+			if len(found) > len(cdc.HRP) {
 
-			err = proto.Error_INCORRECT_HUMAN_READABLE_PART
-			return
+				found = found[:len(cdc.HRP)]
+			}
+
+			return dst, &DecodeError{
+				Kind:        proto.Error_INCORRECT_HUMAN_READABLE_PART,
+				Offset:      offset,
+				ExpectedHRP: cdc.HRP,
+				FoundHRP:    string(found),
+			}
+		}
+
+		// Cut the HRP off the beginning to get the content.
+		input = input[len(cdc.HRP):]
+
+		// People copying codes by hand or out of documents will break them
+		// up with spaces, hyphens and line breaks, so these are not counted,
+		// and the data part may be in either case, but not both.
+		//
+		// The offsets of the first upper and lower case letters are kept, so
+		// that if there are both, the later of them can be given as the
+		// place of the error.
+		var chars int
+		firstUpper, firstLower := -1, -1
+		for i, c := range input {
+
+			switch {
+			case isSeparator[c]:
+				continue
+			case c >= 'A' && c <= 'Z' && firstUpper < 0:
+				firstUpper = i
+			case c >= 'a' && c <= 'z' && firstLower < 0:
+				firstLower = i
+			}
+			chars++
+		}
+
+		if fold && firstUpper >= 0 && firstLower >= 0 {
+
+			offset := firstUpper
+			if firstLower > offset {
+
+				offset = firstLower
+			}
+
+			return dst, decodeError(proto.Error_MIXED_CASE, len(cdc.HRP)+offset)
 		}
 
-		// Cut the HRP off the beginning to get the content, add the initial
-		// zeroed 5 bits with a 'q' character.
+		// Version 0 omits the first character of the encoding, as the input
+		// bytes are always zero for the first 5 most significant bits, so we
+		// must re-add the zero at the front with the first character of the
+		// charset, which is a 'q' in the default charset, before feeding it to
+		// the decoder.
 		//
-		// Be aware the input string will be copied to create the []byte
-		// version. Also, because the input bytes are always zero for the first
-		// 5 most significant bits, we must re-add the zero at the front (q)
-		// before feeding it to the decoder.
-		input = "q" + input[len(cdc.HRP):]
+		// This can be recognised by the length of the data part. Later
+		// versions use all of the bits of the first character and so it is
+		// not omitted.
+		short := chars%8 == 7
+		switch {
+		case short:
+
+			chars++
+
+		case chars%8 != 0:
+
+			return dst, decodeError(proto.Error_INVALID_LENGTH, -1)
+		}
 
 		// The length of the base32 string refers to 5 bits per slice index
 		// position, so the correct size of the output bytes, which are 8 bytes
 		// per slice index position, is found with the following simple integer
 		// math calculation.
 		//
-		// This allocation needs to be made first as the base32 Decode function
-		// does not do this allocation automatically and it would be wasteful to
-		// not compute it precisely, when the calculation is so simple.
-		//
-		// If this allocation is omitted, the decoder will panic due to bounds
-		// check error. A nil slice is equivalent to a zero length slice and
-		// gives a bounds check error, but in fact, the slice has no data at
-		// all. Yes, the panic message is lies:
-		//
-		//   panic: runtime error: index out of range [4] with length 0
+		// This space needs to be made first as the base32 Decode function
+		// does not do this automatically, and will panic due to bounds check
+		// error if it is not there.
 		//
-		// If this assignment isn't made, by default, output is nil, not
-		// []byte{} so this panic message is deceptive.
-		data := make([]byte, len(input)*5/8)
+		// The cleaned up text to decode is assembled after the space for the
+		// decoded bytes, so given enough capacity in dst, nothing is
+		// allocated.
+		start := len(dst)
+		dataLen := chars * 5 / 8
+		buf := grow(dst, dataLen+chars)[:start+dataLen+chars]
+		data, text := buf[start:start+dataLen], buf[start+dataLen:]
+
+		n := 0
+		if short {
+
+			text[0] = cs[0]
+			n++
+		}
+
+		for _, c := range input {
+
+			if isSeparator[c] {
+				continue
+			}
+
+			if fold {
+
+				switch {
+				case foldLower && c >= 'A' && c <= 'Z':
+					c = c - 'A' + 'a'
+				case !foldLower && c >= 'a' && c <= 'z':
+					c = c - 'a' + 'A'
+				}
+			}
+
+			text[n] = c
+			n++
+		}
 
 		var writtenBytes int
-		writtenBytes, err = enc.Decode(data, []byte(input))
+		writtenBytes, err = decode(data, text, &values)
 		if err != nil {
 
-			log.Println(err)
-			return
+			// Finding which character it was takes another pass, which is
+			// only worth doing once there is an error.
+			offset := -1
+			for i, c := range input {
+
+				if isSeparator[c] {
+					continue
+				}
+
+				if fold && foldLower {
+
+					c = toLowerASCII(c)
+				} else if fold && c >= 'a' && c <= 'z' {
+
+					c = c - 'a' + 'A'
+				}
+
+				if values[c] == invalidChar {
+
+					offset = len(cdc.HRP) + i
+					break
+				}
+			}
+
+			// The cause is the base32.CorruptInputError that decode gives,
+			// which callers have always been able to look for, but with the
+			// offset in the input rather than in the cleaned up text.
+			return dst, &DecodeError{
+				Kind:   proto.Error_INVALID_CHARACTER,
+				Offset: offset,
+				Err:    base32.CorruptInputError(offset),
+			}
+		}
+		data = data[:writtenBytes]
+
+		// A string with nothing after the HRP has nothing to decode.
+		if len(data) < 1 {
+
+			return dst, decodeError(proto.Error_ZERO_LENGTH, -1)
+		}
+
+		// The first byte signifies the length of the check at the end, and
+		// the version. A version 0 header in a full length string is not an
+		// encoding any encoder would produce.
+		version, checkLen, compressed := parseHeader(data[0])
+		if !short && version == Version0 {
+
+			return dst, decodeError(proto.Error_INVALID_LENGTH, -1)
 		}
 
-		// The first byte signifies the length of the check at the end
-		checkLen := int(data[0])
 		if writtenBytes < checkLen+1 {
 
-			err = proto.Error_CHECK_TOO_SHORT
-			return
+			return dst, decodeError(proto.Error_CHECK_TOO_SHORT, -1)
 		}
 
 		// Assigning the result of the check here as if true the resulting
 		// decoded bytes still need to be trimmed of the check value (keeping
 		// things cleanly separated between the check and decode function.
-		err = cdc.Check(data)
-
+		//
 		// There is no point in doing any more if the check fails, as per the
 		// contract specified in the interface definition codecer.Codecer
-		if err != nil {
-			return
+		if err = cdc.Check(data); err != nil {
+
+			return dst, wrapDecodeError(err)
 		}
 
 		// Slice off the check length prefix, and the check bytes to return the
-		// valid input bytes.
-		output = data[1:getCutPoint(len(data)+1, checkLen)]
+		// valid input bytes, moving them to the end of dst.
+		payload := data[1:getCutPoint(len(data), checkLen)]
+		if compressed {
+
+			// The decompressed bytes are put in a buffer of their own, so the
+			// payload in buf is not written over while it is being read.
+			if output, err = o.decompress(dst, payload); err != nil {
+
+				return dst, err
+			}
+		} else {
+
+			n = copy(buf[start:], payload)
+			output = buf[:start+n]
+		}
+
+		if o.strict {
+
+			err = canonical(
+				cdc, o, original, output[start:], len(payload), checkLen,
+			)
+			if err != nil {
+
+				return dst, err
+			}
+		}
 
 		// If we got to here, the decode was successful.
 		return
 	}
 
+	// The string form of the decoder is the append form with a new buffer.
+	cdc.Decoder = func(input string) (output []byte, err error) {
+
+		return cdc.AppendDecoder(nil, []byte(input))
+	}
+
 	// We return the value explicitly to be nice to readers as the function is
 	// not a short and simple one.
 	return cdc
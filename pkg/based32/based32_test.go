@@ -4,6 +4,8 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/codecer/codecertest"
 	"lukechampine.com/blake3"
 	"math/rand"
 	"testing"
@@ -181,3 +183,170 @@ func TestCodec(t *testing.T) {
 		}
 	}
 }
+
+func TestAppend(t *testing.T) {
+
+	grouped, err := NewCodec(
+		"Grouped", charset, "QNTRL", WithGrouping(4, "-"), WithUppercase(),
+		WithVersion(Version1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefix := []byte("prefix:")
+	for _, cdc := range []*codec.Codec{Codec, grouped} {
+
+		for l := 1; l < 40; l++ {
+
+			input := make([]byte, l)
+			rand.Read(input)
+
+			// The append form must give the same as the string form, after
+			// whatever is already in dst.
+			encoded, err := cdc.Encode(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			appended, err := cdc.AppendEncode(prefix, input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(appended) != string(prefix)+encoded {
+				t.Fatalf("got '%s' expected '%s%s'", appended, prefix, encoded)
+			}
+
+			decoded, err := cdc.AppendDecode(prefix, []byte(encoded))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(decoded) != string(prefix)+string(input) {
+				t.Fatalf("got %x expected %x%x", decoded, prefix, input)
+			}
+		}
+	}
+
+	// On error dst is returned as it was.
+	dst := make([]byte, 3, 64)
+	out, err := Codec.AppendDecode(dst, []byte("QNTRLqqqqqqq"))
+	if err == nil || len(out) != len(dst) {
+		t.Fatalf("got '%x' with error %v", out, err)
+	}
+}
+
+func TestAppendAllocs(t *testing.T) {
+
+	for _, l := range []int{20, 32} {
+
+		input := make([]byte, l)
+		rand.Read(input)
+		encoded, err := Codec.Encode(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := []byte(encoded)
+		dst := make([]byte, 0, 128)
+
+		if n := testing.AllocsPerRun(100, func() {
+			_, _ = Codec.AppendEncode(dst, input)
+		}); n != 0 {
+			t.Errorf("AppendEncode of %d bytes made %v allocations", l, n)
+		}
+
+		if n := testing.AllocsPerRun(100, func() {
+			_, _ = Codec.AppendDecode(dst, src)
+		}); n != 0 {
+			t.Errorf("AppendDecode of %d bytes made %v allocations", l, n)
+		}
+	}
+}
+
+func benchmarkAppendEncode(b *testing.B, l int) {
+
+	input := make([]byte, l)
+	rand.Read(input)
+	dst := make([]byte, 0, 128)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+
+		_, _ = Codec.AppendEncode(dst, input)
+	}
+}
+
+func benchmarkAppendDecode(b *testing.B, l int) {
+
+	input := make([]byte, l)
+	rand.Read(input)
+	encoded, err := Codec.Encode(input)
+	if err != nil {
+		b.Fatal(err)
+	}
+	src := []byte(encoded)
+	dst := make([]byte, 0, 128)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+
+		_, _ = Codec.AppendDecode(dst, src)
+	}
+}
+
+func BenchmarkAppendEncode20(b *testing.B) { benchmarkAppendEncode(b, 20) }
+func BenchmarkAppendEncode32(b *testing.B) { benchmarkAppendEncode(b, 32) }
+func BenchmarkAppendDecode20(b *testing.B) { benchmarkAppendDecode(b, 20) }
+func BenchmarkAppendDecode32(b *testing.B) { benchmarkAppendDecode(b, 32) }
+
+func BenchmarkEncode32(b *testing.B) {
+
+	input := make([]byte, 32)
+	rand.Read(input)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+
+		_, _ = Codec.Encode(input)
+	}
+}
+
+func BenchmarkDecode32(b *testing.B) {
+
+	input := make([]byte, 32)
+	rand.Read(input)
+	encoded, err := Codec.Encode(input)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+
+		_, _ = Codec.Decode(encoded)
+	}
+}
+
+func TestConformance(t *testing.T) {
+
+	codecertest.Run(t, Codec)
+
+	for name, opts := range map[string][]Option{
+		"Version1": {WithVersion(Version1)},
+		"CRC32C":   {WithChecksum(CRC32C)},
+		"Display": {
+			WithGrouping(4, "-"), WithUppercase(), WithVersion(Version1),
+		},
+	} {
+
+		cdc, err := NewCodec(name, charset, "QNTRL", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run(name, func(t *testing.T) { codecertest.Run(t, cdc) })
+	}
+}
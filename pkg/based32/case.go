@@ -0,0 +1,123 @@
+package based32
+
+import (
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"strings"
+)
+
+// caseFolder returns the function that converts a string to the case of the
+// charset, or nil if the charset has letters of both cases, in which case the
+// case of the input matters and must not be changed.
+func caseFolder(cs string) (fold func(string) string) {
+
+	switch {
+	case strings.ToLower(cs) == cs:
+		return strings.ToLower
+	case strings.ToUpper(cs) == cs:
+		return strings.ToUpper
+	}
+
+	return nil
+}
+
+// hasHRP returns true if the input starts with the Human Readable Part of the
+// codec, in either case if the codec's charset is all one case.
+func hasHRP(cdc *codec.Codec, input string) bool {
+
+	if strings.HasPrefix(input, cdc.HRP) {
+
+		return true
+	}
+
+	return caseFolder(cdc.Charset) != nil &&
+		len(input) >= len(cdc.HRP) &&
+		strings.EqualFold(input[:len(cdc.HRP)], cdc.HRP)
+}
+
+// hrpMismatch returns the offset of the first character of the input that
+// does not match the HRP, which is the length of the input if it is shorter,
+// or -1 if the input starts with the HRP. With fold true the HRP may be in
+// either case, and as the HRP is printable ASCII, the comparison only needs to
+// consider ASCII letters.
+func hrpMismatch(hrp string, input []byte, fold bool) (offset int) {
+
+	for i := 0; i < len(hrp); i++ {
+
+		if i >= len(input) {
+
+			return i
+		}
+
+		a, b := input[i], hrp[i]
+		if a == b {
+			continue
+		}
+
+		if !fold || toLowerASCII(a) != toLowerASCII(b) {
+
+			return i
+		}
+	}
+
+	return -1
+}
+
+// toLowerASCII returns the lower case of an ASCII letter, and any other byte
+// as it is.
+func toLowerASCII(c byte) byte {
+
+	if c >= 'A' && c <= 'Z' {
+
+		return c - 'A' + 'a'
+	}
+
+	return c
+}
+
+// normaliseCase converts the input to the case the codec produces by default,
+// with the HRP as given for the codec and the data part in the case of the
+// charset.
+//
+// Upper case strings can be encoded much more compactly in QR codes, so the
+// data part may be entirely in the other case to the charset, but not a
+// mixture, as that is almost certainly a mistake. Charsets with both cases are
+// left as they are.
+func normaliseCase(cdc *codec.Codec, input string) (output string, err error) {
+
+	if !hasHRP(cdc, input) {
+
+		err = proto.Error_INCORRECT_HUMAN_READABLE_PART
+		return
+	}
+
+	fold := caseFolder(cdc.Charset)
+	if fold == nil {
+
+		output = input
+		return
+	}
+
+	data := input[len(cdc.HRP):]
+
+	var upper, lower bool
+	for i := 0; i < len(data); i++ {
+
+		switch c := data[i]; {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		}
+	}
+
+	if upper && lower {
+
+		err = proto.Error_MIXED_CASE
+		return
+	}
+
+	output = cdc.HRP + fold(data)
+
+	return
+}
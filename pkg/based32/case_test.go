@@ -0,0 +1,74 @@
+package based32
+
+import (
+	"bytes"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"strings"
+	"testing"
+)
+
+func TestCase(t *testing.T) {
+
+	upper, err := NewCodec("Upper", charset, "QNTRL", WithUppercase())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := []byte("printed on a label as a QR code")
+	plain, err := Codec.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var encoded string
+	if encoded, err = upper.Encode(input); err != nil {
+		t.Fatal(err)
+	}
+	if encoded != strings.ToUpper(plain) {
+		t.Fatalf("expected '%s' got '%s'", strings.ToUpper(plain), encoded)
+	}
+
+	// Every single case form must be accepted by both codecs.
+	for _, s := range []string{plain, encoded, strings.ToLower(plain)} {
+
+		for _, decode := range []func(string) ([]byte, error){
+			Codec.Decode, upper.Decode,
+		} {
+
+			var decoded []byte
+			if decoded, err = decode(s); err != nil {
+				t.Fatalf("'%s': %v", s, err)
+			}
+			if !bytes.Equal(decoded, input) {
+				t.Fatalf("got %x expected %x", decoded, input)
+			}
+		}
+	}
+
+	// Changing the case of one letter of the data part must be rejected.
+	mixed := []byte(encoded)
+	for i := len(upper.HRP); i < len(mixed); i++ {
+
+		if mixed[i] >= 'A' && mixed[i] <= 'Z' {
+
+			mixed[i] += 'a' - 'A'
+			break
+		}
+	}
+	if _, err = Codec.Decode(string(mixed)); !errors.Is(err, proto.Error_MIXED_CASE) {
+		t.Fatalf("'%s' gave error %v", mixed, err)
+	}
+
+	// A charset with both cases can't produce single case output, but is
+	// otherwise valid.
+	const mixedCharset = "ABCDEFGHIJKLMNOPabcdefghijklmnop"
+	if _, err = NewCodec("Mixed", mixedCharset, "XYZ-"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewCodec(
+		"Mixed", mixedCharset, "XYZ-", WithUppercase(),
+	); err == nil {
+		t.Fatal("upper case option accepted with mixed case charset")
+	}
+}
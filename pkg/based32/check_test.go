@@ -0,0 +1,43 @@
+package based32
+
+import (
+	"bytes"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+
+	input := []byte("kitchensink")
+	for i := 1; i <= len(input); i++ {
+
+		encoded, err := Codec.Encode(input[:i])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The string that was encoded passes the check, and the check bytes
+		// are cut off the output.
+		decoded, err := Codec.Decode(encoded)
+		if err != nil {
+			t.Fatalf("'%s' gave error %v", encoded, err)
+		}
+		if !bytes.Equal(decoded, input[:i]) {
+			t.Fatalf("'%s' decoded to %x expected %x", encoded, decoded,
+				input[:i],
+			)
+		}
+
+		// Changing a character of the data makes it fail.
+		damaged := []byte(encoded)
+		p := len(Codec.HRP) + 1
+		damaged[p] = charset[(strings.IndexByte(charset, damaged[p])+1)%32]
+		if _, err = Codec.Decode(string(damaged)); !errors.Is(
+			err, proto.Error_CHECK_FAILED,
+		) {
+			t.Fatalf("'%s' gave error %v", damaged, err)
+		}
+	}
+}
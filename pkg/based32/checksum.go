@@ -0,0 +1,132 @@
+package based32
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash/crc32"
+	"lukechampine.com/blake3"
+)
+
+// Checksum identifies the algorithm used to create the check bytes of a codec.
+type Checksum int
+
+const (
+
+	// Blake3 is the default, the Blake3 256 bit hash truncated to the check
+	// length.
+	Blake3 Checksum = iota
+
+	// SHA256 is the SHA-256 hash truncated to the check length, as used for
+	// example by Base58Check (though that is a double hash).
+	SHA256
+
+	// CRC32C is the Castagnoli CRC32 in big endian byte order. As it only
+	// produces 4 bytes, longer check lengths are filled with the CRC32C of the
+	// input followed by the index of the extra 4 byte block.
+	CRC32C
+
+	// KeyedBlake3 is the Blake3 256 bit keyed hash (a MAC) truncated to the
+	// check length, which means only holders of the key can create codes that
+	// pass the check.
+	KeyedBlake3
+)
+
+// String returns the name of the checksum algorithm.
+func (c Checksum) String() string {
+
+	switch c {
+	case Blake3:
+		return "Blake3"
+	case SHA256:
+		return "SHA256"
+	case CRC32C:
+		return "CRC32C"
+	case KeyedBlake3:
+		return "KeyedBlake3"
+	default:
+		return "unknown"
+	}
+}
+
+// checkFunc is the form of the check algorithms. It returns the full length
+// check of the input, of which the first check length bytes are used.
+//
+// Returning an array rather than a slice means the result can stay on the
+// stack of the caller, so creating and verifying checks does not allocate.
+type checkFunc func(input []byte) (sum [maxCheckLen]byte)
+
+// makeCheck adapts a checkFunc to the signature of codec.Codec.MakeCheck.
+func makeCheck(check checkFunc) func(input []byte, checkLen int) []byte {
+
+	return func(input []byte, checkLen int) (output []byte) {
+
+		sum := check(input)
+		output = make([]byte, checkLen)
+		copy(output, sum[:])
+
+		return
+	}
+}
+
+// blake3Check returns the Blake3 hash of the input.
+func blake3Check(input []byte) (sum [maxCheckLen]byte) {
+
+	// We use the Blake3 256 bit hash because it is nearly as fast as CRC32
+	// but less complicated to use due to the 32 bit integer conversions to
+	// bytes required to use the CRC32 algorithm.
+	return blake3.Sum256(input)
+}
+
+// sha256Check returns the SHA-256 hash of the input.
+func sha256Check(input []byte) (sum [maxCheckLen]byte) {
+
+	return sha256.Sum256(input)
+}
+
+// castagnoli is the CRC32-C table, which is hardware accelerated on most
+// platforms.
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// blockIndex holds the index of each 4 byte block of an extended CRC32-C check,
+// to be sliced from without allocating.
+var blockIndex = [maxCheckLen / 4]byte{0, 1, 2, 3, 4, 5, 6, 7}
+
+// crc32cCheck returns the big endian CRC32-C of the input, extended to the full
+// length by further 4 byte blocks.
+func crc32cCheck(input []byte) (sum [maxCheckLen]byte) {
+
+	crc := crc32.Checksum(input, castagnoli)
+	binary.BigEndian.PutUint32(sum[:], crc)
+
+	for i := 1; i < len(blockIndex); i++ {
+
+		// Continuing the CRC from the value of the input alone means the first
+		// block is the same as a plain CRC32-C, which keeps short checks
+		// compatible with other implementations.
+		binary.BigEndian.PutUint32(
+			sum[i*4:], crc32.Update(crc, castagnoli, blockIndex[i:i+1]),
+		)
+	}
+
+	return
+}
+
+// keyedBlake3Check returns a check function that computes the Blake3 keyed hash
+// of the input with the given key.
+func keyedBlake3Check(key []byte) checkFunc {
+
+	// Copy the key so the caller changing their slice later can't change the
+	// codec.
+	k := make([]byte, len(key))
+	copy(k, key)
+
+	return func(input []byte) (sum [maxCheckLen]byte) {
+
+		// The key has already been validated so this can't fail.
+		h := blake3.New(maxCheckLen, k)
+		_, _ = h.Write(input)
+		h.Sum(sum[:0])
+
+		return
+	}
+}
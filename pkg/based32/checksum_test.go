@@ -0,0 +1,92 @@
+package based32
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"lukechampine.com/blake3"
+	"testing"
+)
+
+func TestChecksums(t *testing.T) {
+
+	// The first 4 bytes of the CRC32C check must be the standard CRC32-C, the
+	// check value for which is given for the string "123456789".
+	crc := crc32cCheck([]byte("123456789"))
+	if hex.EncodeToString(crc[:4]) != "e3069283" {
+		t.Fatalf("incorrect CRC32-C %x", crc)
+	}
+
+	key := blake3.Sum256([]byte("kitchensink"))
+	codecs := make(map[Checksum]*codec.Codec)
+	for checksum, opt := range map[Checksum]Option{
+		Blake3:      WithChecksum(Blake3),
+		SHA256:      WithChecksum(SHA256),
+		CRC32C:      WithChecksum(CRC32C),
+		KeyedBlake3: WithKeyedBlake3(key[:]),
+	} {
+
+		cdc, err := NewCodec(checksum.String(), charset, "QNTRL", opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cdc.CheckName != checksum.String() {
+			t.Fatalf("expected check name %s got %s", checksum, cdc.CheckName)
+		}
+		codecs[checksum] = cdc
+	}
+
+	// The default must be the same as the package Codec.
+	if Codec.CheckName != Blake3.String() {
+		t.Fatalf("package codec check is %s", Codec.CheckName)
+	}
+
+	last := blake3.Sum256([]byte("checksums"))
+	for i := 0; i < 10; i++ {
+
+		input := last[:len(last)-i%5]
+		last = blake3.Sum256(last[:])
+
+		blake3Encoded, err := Codec.Encode(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for checksum, cdc := range codecs {
+
+			var encoded string
+			if encoded, err = cdc.Encode(input); err != nil {
+				t.Fatal(err)
+			}
+
+			var decoded []byte
+			if decoded, err = cdc.Decode(encoded); err != nil {
+				t.Fatalf("%s: '%s' %v", checksum, encoded, err)
+			}
+			if !bytes.Equal(decoded, input) {
+				t.Fatalf("%s: got %x expected %x", checksum, decoded, input)
+			}
+
+			// Each algorithm must reject the output of the others.
+			if checksum == Blake3 {
+
+				if encoded != blake3Encoded {
+					t.Fatalf("Blake3 option differs from package codec")
+				}
+				continue
+			}
+			if _, err = cdc.Decode(blake3Encoded); err == nil {
+				t.Fatalf("%s: accepted Blake3 '%s'", checksum, blake3Encoded)
+			}
+		}
+	}
+
+	for _, opt := range []Option{
+		WithKeyedBlake3(nil), WithChecksum(KeyedBlake3), WithChecksum(-1),
+	} {
+
+		if _, err := NewCodec("bad", charset, "QNTRL", opt); err == nil {
+			t.Fatal("invalid checksum option accepted")
+		}
+	}
+}
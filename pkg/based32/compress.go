@@ -0,0 +1,134 @@
+package based32
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"io"
+)
+
+// DefaultMaxDecompressedSize is the largest size, in bytes, that the decoders
+// decompress data to unless WithMaxDecompressedSize says otherwise.
+const DefaultMaxDecompressedSize = 64 << 10
+
+// WithCompression makes the encoder compress the data with DEFLATE before it
+// is encoded, if that makes it smaller, and set the compression flag in the
+// header so the decoder knows to decompress it. Repetitive text and JSON often
+// shrink to a fraction of their size, while short or random data is left as it
+// is, so it costs nothing to turn on.
+//
+// Compression needs Version1 or later, as version 0 has no room in the header
+// for the flag. Codecs always decompress compressed strings regardless of this
+// option, up to the limit set by WithMaxDecompressedSize.
+func WithCompression() Option {
+
+	return func(o *options) (err error) {
+
+		o.compression = true
+		return
+	}
+}
+
+// WithMaxDecompressedSize sets the largest size, in bytes, that the decoder
+// will decompress data to, so that a small string can't make it use a lot of
+// memory by decompressing to something enormous. Data that would be larger is
+// rejected with DECOMPRESSED_TOO_LARGE. The default is
+// DefaultMaxDecompressedSize.
+func WithMaxDecompressedSize(size int) Option {
+
+	return func(o *options) (err error) {
+
+		if size < 1 {
+
+			err = fmt.Errorf(
+				"maximum decompressed size must be at least 1, got %d", size,
+			)
+			return
+		}
+
+		o.maxDecompressed = size
+
+		return
+	}
+}
+
+// compress returns the data to encode for the input, which is the input
+// compressed with DEFLATE if the codec compresses and that is shorter, or
+// otherwise the input itself.
+func (o options) compress(input []byte) (payload []byte, compressed bool) {
+
+	if !o.compression {
+
+		return input, false
+	}
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+
+		return input, false
+	}
+
+	// Writing to a bytes.Buffer can't fail.
+	_, _ = w.Write(input)
+	_ = w.Close()
+
+	if buf.Len() >= len(input) {
+
+		return input, false
+	}
+
+	return buf.Bytes(), true
+}
+
+// decompress appends the decompressed payload to dst. Payloads that are not
+// valid DEFLATE data give INVALID_COMPRESSION, as do those with anything after
+// the end of the compressed data, as that would let many strings decode to the
+// same data. Those that decompress to more than the maximum size give
+// DECOMPRESSED_TOO_LARGE, and to nothing ZERO_LENGTH, as empty data can't be
+// encoded.
+func (o options) decompress(dst, payload []byte) (output []byte, err error) {
+
+	br := bytes.NewReader(payload)
+	r := flate.NewReader(br)
+	defer r.Close()
+
+	// Reading one byte more than the maximum is enough to know the data is
+	// too large, without reading the rest of it.
+	var buf bytes.Buffer
+	if _, err = io.Copy(
+		&buf, io.LimitReader(r, int64(o.maxDecompressed)+1),
+	); err != nil {
+
+		return dst, &DecodeError{
+			Kind:   proto.Error_INVALID_COMPRESSION,
+			Offset: -1,
+			Err:    err,
+		}
+	}
+
+	if buf.Len() > o.maxDecompressed {
+
+		return dst, decodeError(proto.Error_DECOMPRESSED_TOO_LARGE, -1)
+	}
+
+	// The reader stops at the end of the compressed data, so anything left in
+	// the payload was added after it.
+	if br.Len() > 0 {
+
+		return dst, &DecodeError{
+			Kind:   proto.Error_INVALID_COMPRESSION,
+			Offset: -1,
+			Err:    errors.New("data after the end of the compressed data"),
+		}
+	}
+
+	if buf.Len() == 0 {
+
+		return dst, decodeError(proto.Error_ZERO_LENGTH, -1)
+	}
+
+	return append(dst, buf.Bytes()...), nil
+}
@@ -0,0 +1,250 @@
+package based32
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"lukechampine.com/blake3"
+	"testing"
+)
+
+// repetitive returns JSON of about the given size, which compresses well.
+func repetitive(size int) (input []byte) {
+
+	input = []byte("[")
+	for i := 0; len(input) < size; i++ {
+
+		input = append(input, fmt.Sprintf(
+			`{"id":%d,"name":"item %d","active":true},`, i, i,
+		)...)
+	}
+	input[len(input)-1] = ']'
+
+	return
+}
+
+// deflate returns the input compressed with DEFLATE at the given level.
+func deflate(t *testing.T, input []byte, level int) []byte {
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write(input); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// compressedString returns a version 1 string for the default codec with the
+// compression flag set and the given payload, with a correct check.
+func compressedString(payload []byte) string {
+
+	o := defaultOptions()
+	o.version = Version1
+	raw := appendRaw(nil, payload, true, o)
+
+	return "QNTRL" + base32.NewEncoding(charset).EncodeToString(raw)
+}
+
+func TestCompression(t *testing.T) {
+
+	z, err := NewCodec("Compressed", charset, "QNTRL",
+		WithVersion(Version1), WithCompression(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1, err := NewCodec("Version1", charset, "QNTRL", WithVersion(Version1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := repetitive(4096)
+	compressed, err := z.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := v1.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compressed)*4 > len(plain) {
+		t.Fatalf("compressed to %d characters from %d",
+			len(compressed), len(plain),
+		)
+	}
+
+	// Every codec decompresses, whether it compresses or not.
+	for _, cdc := range []interface {
+		Decode(string) ([]byte, error)
+	}{z, v1, Codec} {
+
+		decoded, err := cdc.Decode(compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Fatalf("'%s' decoded to '%s'", compressed, decoded)
+		}
+	}
+
+	// Data that doesn't get smaller is encoded as it is.
+	random := blake3.Sum256([]byte("compression"))
+	for i := 1; i <= len(random); i++ {
+
+		if compressed, err = z.Encode(random[:i]); err != nil {
+			t.Fatal(err)
+		}
+		if plain, err = v1.Encode(random[:i]); err != nil {
+			t.Fatal(err)
+		}
+		if compressed != plain {
+			t.Fatalf("got '%s' expected '%s'", compressed, plain)
+		}
+	}
+
+	// Strict mode expects the data to be compressed as the encoder does it.
+	strict, err := NewCodec("Strict", charset, "QNTRL",
+		WithVersion(Version1), WithCompression(), WithStrict(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compressed, err = strict.Encode(input); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = strict.Decode(compressed); err != nil {
+		t.Fatal(err)
+	}
+
+	// Word lists compress the same way.
+	words, err := NewMnemonicCodec("Words", English,
+		WithVersion(Version1), WithCompression(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compressed, err = words.Encode(input); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Mnemonic.Decode(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Fatalf("'%s' decoded to '%s'", compressed, decoded)
+	}
+}
+
+func TestDecompressionErrors(t *testing.T) {
+
+	z, err := NewCodec("Compressed", charset, "QNTRL",
+		WithVersion(Version1), WithCompression(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := repetitive(2048)
+	encoded, err := z.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limited, err := NewCodec("Limited", charset, "QNTRL",
+		WithMaxDecompressedSize(len(input)-1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = limited.Decode(encoded); !errors.Is(
+		err, proto.Error_DECOMPRESSED_TOO_LARGE,
+	) {
+		t.Fatalf("got error %v", err)
+	}
+
+	// Exactly the maximum is allowed.
+	limited, err = NewCodec("Limited", charset, "QNTRL",
+		WithMaxDecompressedSize(len(input)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = limited.Decode(encoded); err != nil {
+		t.Fatal(err)
+	}
+
+	strict, err := NewCodec("Strict", charset, "QNTRL",
+		WithVersion(Version1), WithCompression(), WithStrict(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Payloads that no encoder of the package would produce, with a correct
+	// check, as only a faulty or malicious encoder would: one that is not
+	// DEFLATE data, one with bytes after the end of it, one that decompresses
+	// to nothing, and one compressed differently to the encoder.
+	clean := deflate(t, input, flate.BestCompression)
+	for _, c := range []struct {
+		payload []byte
+		kind    proto.Error
+		strict  proto.Error
+	}{
+		{[]byte{0xff, 0xff, 0xff}, proto.Error_INVALID_COMPRESSION,
+			proto.Error_INVALID_COMPRESSION},
+		{append(clean[:len(clean):len(clean)], 0xde, 0xad, 0xbe, 0xef),
+			proto.Error_INVALID_COMPRESSION, proto.Error_INVALID_COMPRESSION},
+		{deflate(t, nil, flate.BestCompression), proto.Error_ZERO_LENGTH,
+			proto.Error_ZERO_LENGTH},
+		{deflate(t, input, flate.BestSpeed), -1,
+			proto.Error_NON_CANONICAL},
+	} {
+
+		bad := compressedString(c.payload)
+		for _, d := range []struct {
+			cdc  interface{ Decode(string) ([]byte, error) }
+			kind proto.Error
+		}{{Codec, c.kind}, {strict, c.strict}} {
+
+			_, err = d.cdc.Decode(bad)
+			if d.kind < 0 {
+
+				if err != nil {
+					t.Fatalf("'%s' gave error %v", bad, err)
+				}
+				continue
+			}
+
+			if !errors.Is(err, d.kind) {
+				t.Fatalf("'%s' gave error %v expected %v", bad, err, d.kind)
+			}
+		}
+	}
+
+	// The flag can't be set in version 0.
+	if err = checkVersion(Version0, true); !errors.Is(
+		err, proto.Error_UNSUPPORTED_VERSION,
+	) {
+		t.Fatalf("got error %v", err)
+	}
+
+	for _, opts := range [][]Option{
+		{WithCompression()},
+		{WithMaxDecompressedSize(0)},
+	} {
+
+		if _, err = NewCodec("Bad", charset, "QNTRL", opts...); err == nil {
+			t.Fatal("codec was created with invalid options")
+		}
+	}
+}
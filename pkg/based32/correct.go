@@ -0,0 +1,423 @@
+package based32
+
+import (
+	"errors"
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"sort"
+	"strings"
+)
+
+// MaxCorrectable is the largest number of errors a Corrector can be made to
+// correct. Each code of a Corrector has two parity symbols for each error it
+// can correct, and at most 31 symbols, and there must be room for at least one
+// symbol of data.
+const MaxCorrectable = (gfSize - 1) / 2
+
+// Corrector is a codec that adds Reed–Solomon parity symbols to the end of
+// the strings of a based32 codec, so that characters that are copied wrongly,
+// or can't be read from a smudged printout, are corrected when it is decoded
+// rather than only detected.
+//
+// The data part of the string is split into as few codes as can hold it, each
+// of at most 31 symbols with its parity. The characters, data and then parity,
+// are dealt out to the codes in turn, so that a run of neighbouring characters
+// that are all wrong is spread over all of them. Any wrong characters up to the
+// number the Corrector was made for are corrected wherever they are, and longer
+// runs of neighbouring ones as well, up to that number for each code.
+//
+// Characters that are not in the charset, such as a '?' written in place of
+// one that can't be read, are known to be wrong, and are corrected as erasures.
+// As their places are known, twice as many of them can be corrected as wrong
+// characters that are in the charset.
+//
+// The data part before the parity symbols is the string the based32 codec
+// encodes, and it is still checked by that codec once it has been corrected,
+// so that too many errors, which may be corrected into the wrong data, are
+// still found.
+//
+// The embedded codec encodes and decodes the strings with the parity, so a
+// Corrector can be used wherever a codec.Codec or codecer.Codecer can.
+type Corrector struct {
+	*codec.Codec
+
+	// inner is the codec of the data part, which has no grouping, as the
+	// groups are made once the parity is added.
+	inner *codec.Codec
+
+	o           options
+	parity      int
+	gen         []byte
+	values      [256]byte
+	isSeparator [256]bool
+	fold        bool
+}
+
+// NewCorrector creates a Corrector for the based32 codec with the given name,
+// charset, Human Readable Part and options, as for NewCodec, which corrects up
+// to the given number of wrong characters, from 1 to MaxCorrectable. Each
+// error that can be corrected makes the string at least two characters longer.
+//
+// WithStrict can't be used, as correcting errors means different strings
+// decode to the same data, which strict mode forbids.
+func NewCorrector(name, cs, hrp string, correctable int, opts ...Option) (
+	c *Corrector, err error,
+) {
+
+	if err = validate(name, cs, hrp); err != nil {
+		return
+	}
+
+	if correctable < 1 || correctable > MaxCorrectable {
+
+		err = fmt.Errorf(
+			"correctable errors must be between 1 and %d, got %d",
+			MaxCorrectable, correctable,
+		)
+		return
+	}
+
+	var o options
+	if o, err = buildOptions(cs, opts); err != nil {
+		return
+	}
+
+	if o.strict {
+
+		err = errors.New("a Corrector can't be strict")
+		return
+	}
+
+	inner := o
+	inner.groupSize, inner.groupSeparator = 0, ""
+
+	c = &Corrector{
+		inner:  makeCodec(name, cs, hrp, inner),
+		o:      o,
+		parity: 2 * correctable,
+		gen:    rsGenerator(2 * correctable),
+		fold:   caseFolder(cs) != nil,
+	}
+
+	for i := range c.values {
+
+		c.values[i] = invalidChar
+	}
+	for i := 0; i < len(cs); i++ {
+
+		ch := cs[i]
+		c.values[ch] = byte(i)
+		if c.fold {
+
+			switch {
+			case ch >= 'a' && ch <= 'z':
+				c.values[ch-'a'+'A'] = byte(i)
+			case ch >= 'A' && ch <= 'Z':
+				c.values[ch-'A'+'a'] = byte(i)
+			}
+		}
+	}
+
+	separators := DefaultSeparators + o.groupSeparator
+	for i := 0; i < len(separators); i++ {
+
+		c.isSeparator[separators[i]] = strings.IndexByte(cs, separators[i]) < 0
+	}
+
+	c.Codec = &codec.Codec{
+		Name:      name,
+		HRP:       hrp,
+		Charset:   cs,
+		CheckName: c.inner.CheckName,
+		MakeCheck: c.inner.MakeCheck,
+		Check:     c.inner.Check,
+		Encoder:   c.encode,
+		Decoder: func(input string) (output []byte, err error) {
+
+			output, _, err = c.Repair(input)
+			return
+		},
+	}
+
+	return
+}
+
+// blocks returns the number of codes the given number of data symbols is
+// split into.
+func (c *Corrector) blocks(symbols int) int {
+
+	size := gfSize - c.parity
+
+	return (symbols + size - 1) / size
+}
+
+// parityStart returns the index, counting from the end of the data symbols,
+// of the first parity symbol of code j when there are n data symbols. Every
+// symbol of the string is dealt to the codes in turn, so that any run of
+// neighbouring symbols, including one that runs from the data into the
+// parity, is spread evenly over the codes.
+func (c *Corrector) parityStart(n, j int) int {
+
+	blocks := c.blocks(n)
+
+	return (j - n%blocks + blocks) % blocks
+}
+
+// encode encodes the input with the inner codec and adds the parity symbols.
+func (c *Corrector) encode(input []byte) (output string, err error) {
+
+	var b []byte
+	if b, err = c.inner.AppendEncode(nil, input); err != nil {
+		return
+	}
+
+	data := b[len(c.HRP):]
+	symbols := make([]byte, len(data))
+	for i := range data {
+
+		symbols[i] = c.values[data[i]]
+	}
+
+	// The parity symbols are dealt out after the data, carrying on from the
+	// code the last data symbol was dealt to.
+	blocks := c.blocks(len(symbols))
+	parity := make([]byte, c.parity*blocks)
+	for j := 0; j < blocks; j++ {
+
+		first := c.parityStart(len(symbols), j)
+
+		var block []byte
+		for i := j; i < len(symbols); i += blocks {
+
+			block = append(block, symbols[i])
+		}
+
+		for p, v := range rsParity(c.gen, block) {
+
+			parity[p*blocks+first] = v
+		}
+	}
+
+	for _, v := range parity {
+
+		ch := c.Charset[v]
+		if c.o.uppercase && ch >= 'a' && ch <= 'z' {
+
+			ch = ch - 'a' + 'A'
+		}
+		b = append(b, ch)
+	}
+
+	if c.o.groupSize > 0 {
+
+		b = groupInPlace(
+			b, len(c.HRP), c.HRP != "", c.o.groupSize, c.o.groupSeparator,
+		)
+	}
+
+	return string(b), nil
+}
+
+// symbols returns the values of the characters of the data part of the input,
+// and the offset in the input of each one, leaving out the separators. The
+// characters that are not in the charset are given the value zero, and their
+// indexes in the symbols are returned as the erasures.
+func (c *Corrector) symbols(input string) (
+	symbols []byte, offsets, erasures []int, err error,
+) {
+
+	if offset := hrpMismatch(c.HRP, []byte(input), c.fold); offset >= 0 {
+
+		found := input
+		if len(found) > len(c.HRP) {
+
+			found = found[:len(c.HRP)]
+		}
+
+		err = &DecodeError{
+			Kind:        proto.Error_INCORRECT_HUMAN_READABLE_PART,
+			Offset:      offset,
+			ExpectedHRP: c.HRP,
+			FoundHRP:    found,
+		}
+		return
+	}
+
+	firstUpper, firstLower := -1, -1
+	for i := len(c.HRP); i < len(input); i++ {
+
+		ch := input[i]
+		if c.isSeparator[ch] {
+			continue
+		}
+
+		if c.values[ch] == invalidChar {
+
+			erasures = append(erasures, len(symbols))
+			symbols = append(symbols, 0)
+			offsets = append(offsets, i)
+			continue
+		}
+
+		switch {
+		case ch >= 'A' && ch <= 'Z' && firstUpper < 0:
+			firstUpper = i
+		case ch >= 'a' && ch <= 'z' && firstLower < 0:
+			firstLower = i
+		}
+
+		symbols = append(symbols, c.values[ch])
+		offsets = append(offsets, i)
+	}
+
+	if c.fold && firstUpper >= 0 && firstLower >= 0 {
+
+		offset := firstUpper
+		if firstLower > offset {
+
+			offset = firstLower
+		}
+
+		return nil, nil, nil, decodeError(proto.Error_MIXED_CASE, offset)
+	}
+
+	return
+}
+
+// dataLen returns the number of data symbols in a data part of the given
+// length, or -1 if no number of data symbols makes that length with the
+// parity added.
+func (c *Corrector) dataLen(length int) int {
+
+	for blocks := 1; blocks*c.parity < length; blocks++ {
+
+		if n := length - blocks*c.parity; c.blocks(n) == blocks {
+
+			return n
+		}
+	}
+
+	return -1
+}
+
+// Repair decodes the input, correcting any wrong characters it can, and
+// returns the offsets in the input of the characters that it corrected,
+// counting the Human Readable Part and any separators, in order. These include
+// all of the characters that are not in the charset.
+//
+// If there are more errors than can be corrected, CHECK_FAILED is returned,
+// either because the parity shows it, or because the corrected data fails the
+// check of the based32 codec, unless there were characters not in the charset,
+// in which case INVALID_CHARACTER is returned with the offset of the first of
+// them. The errors are DecodeErrors, as for the decoders of the package.
+func (c *Corrector) Repair(input string) (
+	output []byte, fixed []int, err error,
+) {
+
+	var symbols []byte
+	var offsets, erasures []int
+	if symbols, offsets, erasures, err = c.symbols(input); err != nil {
+		return
+	}
+
+	// Too many errors to correct are blamed on the first character that is
+	// not in the charset, if there is one, as that is the first thing the
+	// user should look at.
+	failed := func() error {
+
+		if len(erasures) > 0 {
+
+			return decodeError(
+				proto.Error_INVALID_CHARACTER, offsets[erasures[0]],
+			)
+		}
+
+		return decodeError(proto.Error_CHECK_FAILED, -1)
+	}
+
+	if len(symbols) < 1 {
+
+		return nil, nil, decodeError(proto.Error_ZERO_LENGTH, -1)
+	}
+
+	n := c.dataLen(len(symbols))
+	if n < 1 {
+
+		return nil, nil, decodeError(proto.Error_INVALID_LENGTH, -1)
+	}
+
+	// Each code is gathered from the places its symbols were dealt to,
+	// corrected, and the corrections put back in the same places.
+	blocks := c.blocks(n)
+	for j := 0; j < blocks; j++ {
+
+		var places []int
+		for i := j; i < n; i += blocks {
+
+			places = append(places, i)
+		}
+		first := c.parityStart(n, j)
+		for p := 0; p < c.parity; p++ {
+
+			places = append(places, n+p*blocks+first)
+		}
+
+		codeword := make([]byte, len(places))
+		var erased []int
+		for i, place := range places {
+
+			codeword[i] = symbols[place]
+			if k := sort.SearchInts(erasures, place); k < len(erasures) &&
+				erasures[k] == place {
+
+				erased = append(erased, i)
+			}
+		}
+
+		var corrected []int
+		if corrected, err = rsCorrect(codeword, c.parity, erased); err != nil {
+
+			return nil, nil, failed()
+		}
+
+		for _, i := range corrected {
+
+			symbols[places[i]] = codeword[i]
+			fixed = append(fixed, offsets[places[i]])
+		}
+	}
+
+	// The corrected data part is decoded by the inner codec, which checks it.
+	text := make([]byte, 0, len(c.HRP)+n)
+	text = append(text, input[:len(c.HRP)]...)
+	for _, v := range symbols[:n] {
+
+		text = append(text, c.Charset[v])
+	}
+
+	if output, err = c.inner.AppendDecode(nil, text); err != nil {
+
+		if errors.Is(err, proto.Error_CHECK_FAILED) {
+
+			return nil, nil, failed()
+		}
+
+		// The offsets of errors in the data part are moved to where the
+		// characters are in the input.
+		var de *DecodeError
+		if errors.As(err, &de) && de.Offset >= len(c.HRP) &&
+			de.Offset < len(c.HRP)+n {
+
+			de.Offset = offsets[de.Offset-len(c.HRP)]
+		}
+
+		return nil, nil, err
+	}
+
+	// The codes are corrected one after another, so the offsets are sorted
+	// to put them in the order they are in the input.
+	sort.Ints(fixed)
+
+	return
+}
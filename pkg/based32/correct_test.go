@@ -0,0 +1,225 @@
+package based32
+
+import (
+	"bytes"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/codecer"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var _ codecer.Codecer = &Corrector{}
+
+func TestCorrector(t *testing.T) {
+
+	c, err := NewCorrector("Correcting", charset, "QNTRL", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(2))
+	for l := 1; l <= 64; l++ {
+
+		input := make([]byte, l)
+		rng.Read(input)
+
+		encoded, err := c.Encode(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The string starts with the encoding of the based32 codec.
+		plain, err := c.inner.Encode(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(encoded, plain) {
+			t.Fatalf("'%s' does not start with '%s'", encoded, plain)
+		}
+
+		decoded, fixed, err := c.Repair(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, input) || fixed != nil {
+			t.Fatalf("'%s' gave %x and %v", encoded, decoded, fixed)
+		}
+
+		// Any two characters are corrected, as is a run of one character
+		// for each code, twice.
+		blocks := c.blocks(len(plain) - len(c.HRP))
+		positions := rng.Perm(len(encoded) - len(c.HRP))[:2]
+		for i := range positions {
+
+			positions[i] += len(c.HRP)
+		}
+		sort.Ints(positions)
+		runStart := len(c.HRP) + rng.Intn(len(encoded)-len(c.HRP)-2*blocks+1)
+		var run []int
+		for i := 0; i < 2*blocks; i++ {
+
+			run = append(run, runStart+i)
+		}
+
+		for _, wrong := range [][]int{positions, run} {
+
+			damaged := []byte(encoded)
+			for _, p := range wrong {
+
+				damaged[p] = charset[(strings.IndexByte(charset, damaged[p])+
+					1+rng.Intn(31))%32]
+			}
+
+			decoded, fixed, err = c.Repair(string(damaged))
+			if err != nil {
+				t.Fatalf("'%s' damaged to '%s': %v", encoded, damaged, err)
+			}
+			if !bytes.Equal(decoded, input) {
+				t.Fatalf("'%s' gave %x expected %x", damaged, decoded, input)
+			}
+			if !reflect.DeepEqual(fixed, wrong) {
+				t.Fatalf("'%s' fixed %v expected %v", damaged, fixed, wrong)
+			}
+
+			if decoded, err = c.Decode(string(damaged)); err != nil ||
+				!bytes.Equal(decoded, input) {
+				t.Fatalf("'%s' gave %x and error %v", damaged, decoded, err)
+			}
+		}
+
+		// Characters that are not in the charset are erasures, and twice as
+		// many of them are corrected, or one wrong character and two of them.
+		erased := rng.Perm(len(encoded) - len(c.HRP))[:4]
+		for i := range erased {
+
+			erased[i] += len(c.HRP)
+		}
+		for _, wrong := range [][]int{erased, erased[1:]} {
+
+			damaged := []byte(encoded)
+			for _, p := range wrong {
+
+				damaged[p] = '?'
+			}
+			if p := wrong[0]; len(wrong) < 4 {
+
+				damaged[p] = charset[(strings.IndexByte(charset, encoded[p])+
+					1)%32]
+			}
+			expected := append([]int{}, wrong...)
+			sort.Ints(expected)
+
+			decoded, fixed, err = c.Repair(string(damaged))
+			if err != nil {
+				t.Fatalf("'%s' damaged to '%s': %v", encoded, damaged, err)
+			}
+			if !bytes.Equal(decoded, input) ||
+				!reflect.DeepEqual(fixed, expected) {
+				t.Fatalf("'%s' gave %x fixed %v expected %v", damaged,
+					decoded, fixed, expected,
+				)
+			}
+		}
+	}
+}
+
+func TestCorrectorOptions(t *testing.T) {
+
+	c, err := NewCorrector("Grouped", charset, "QNTRL", 1,
+		WithGrouping(4, "-"), WithUppercase(), WithVersion(Version1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := []byte("kitchensink")
+	encoded, err := c.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != strings.ToUpper(encoded) || encoded[5] != '-' {
+		t.Fatalf("'%s' is not grouped and upper case", encoded)
+	}
+
+	// The offsets count the separators, and either case is accepted.
+	damaged := []byte(strings.ToLower(encoded))
+	damaged[7] = 'q'
+	if encoded[7] == 'Q' {
+
+		damaged[7] = 'p'
+	}
+	decoded, fixed, err := c.Repair(string(damaged))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, input) || !reflect.DeepEqual(fixed, []int{7}) {
+		t.Fatalf("'%s' gave %x fixed %v", damaged, decoded, fixed)
+	}
+
+	for _, c := range []struct {
+		correctable int
+		opts        []Option
+	}{
+		{0, nil},
+		{MaxCorrectable + 1, nil},
+		{1, []Option{WithStrict()}},
+	} {
+
+		if _, err = NewCorrector("Bad", charset, "QNTRL", c.correctable,
+			c.opts...,
+		); err == nil {
+			t.Fatalf("corrector for %d errors with %d options was created",
+				c.correctable, len(c.opts),
+			)
+		}
+	}
+}
+
+func TestCorrectorErrors(t *testing.T) {
+
+	c, err := NewCorrector("Correcting", charset, "QNTRL", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := c.Encode([]byte("kitchensink"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := encoded[len(c.HRP):]
+
+	// Far more errors than can be corrected.
+	reversed := []byte(data)
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+
+	for _, e := range []struct {
+		input  string
+		kind   proto.Error
+		offset int
+	}{
+		{"QNTRX" + data, proto.Error_INCORRECT_HUMAN_READABLE_PART, 4},
+		{"QNTRL", proto.Error_ZERO_LENGTH, -1},
+		{"QNTRLqq", proto.Error_INVALID_LENGTH, -1},
+		{"QNTRL" + data[:3] + "bbb" + data[6:], proto.Error_INVALID_CHARACTER,
+			8},
+		{"QNTRL" + data[:3] + strings.ToUpper(data[3:]),
+			proto.Error_MIXED_CASE, 8},
+		{"QNTRL" + string(reversed), proto.Error_CHECK_FAILED, -1},
+	} {
+
+		_, _, err = c.Repair(e.input)
+		var de *DecodeError
+		if !errors.As(err, &de) || de.Kind != e.kind || de.Offset != e.offset {
+			t.Fatalf("'%s' gave error %v expected %v at %d",
+				e.input, err, e.kind, e.offset,
+			)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package based32
+
+import (
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"sort"
+)
+
+// Mistake is the kind of typing error that a Correction undoes.
+type Mistake int
+
+const (
+
+	// Substitution is a single character typed in place of another.
+	Substitution Mistake = iota
+
+	// Transposition is two adjacent characters typed in the wrong order.
+	Transposition
+
+	// Insertion is an extra character typed that should not be there.
+	Insertion
+
+	// Deletion is a character that was left out.
+	Deletion
+)
+
+// String returns the name of the kind of mistake.
+func (m Mistake) String() string {
+
+	switch m {
+	case Substitution:
+		return "substitution"
+	case Transposition:
+		return "transposition"
+	case Insertion:
+		return "insertion"
+	case Deletion:
+		return "deletion"
+	default:
+		return "unknown"
+	}
+}
+
+// Correction is one way a string that failed to decode can be edited so that
+// it passes the check.
+//
+// Position is the zero based offset into the string that was given to
+// Diagnose, counting the Human Readable Part and any separators in it. Found is
+// the character at that position in the input, which is empty for a Deletion
+// at the end of the string, and Expected is the character that should be there
+// instead, which is empty for an Insertion. For a Transposition, Position is
+// the first of the two swapped characters and Found and Expected are the pair
+// as found and as corrected. Corrected is the whole string as corrected, with
+// the separators removed.
+type Correction struct {
+	Mistake   Mistake
+	Position  int
+	Found     string
+	Expected  string
+	Corrected string
+	Output    []byte
+}
+
+// Diagnose decodes the input with the given codec, and if this fails, searches
+// for the single typing mistakes that could have produced the input from a
+// valid string: every substitution of one character with another from the
+// charset, every swap of two adjacent characters, and every single character
+// insertion or deletion within the data part.
+//
+// If the input decodes correctly the output is returned with no corrections.
+// Otherwise, the original error is returned along with the corrections that
+// result in a string that passes the check, ordered by position. With the
+// shorter check lengths more than one correction can pass by chance, so these
+// should be offered to the user as suggestions, not applied automatically.
+//
+// An incorrect Human Readable Part is not searched, as the HRP is not covered
+// by the check and so there is nothing to confirm a guess with.
+func Diagnose(cdc *codec.Codec, input string) (
+	output []byte, corrections []Correction, err error,
+) {
+
+	if output, err = cdc.Decode(input); err == nil {
+		return
+	}
+
+	if !hasHRP(cdc, input) {
+
+		err = proto.Error_INCORRECT_HUMAN_READABLE_PART
+		return
+	}
+
+	// Separators would throw off the positions and the lengths of the
+	// candidates, so they are removed first, and the candidates are built
+	// from the charset so the input must be in the same case.
+	stripped := stripSeparators(cdc, input, DefaultSeparators)
+
+	// The positions of the corrections are those in the input as it was
+	// given, so that they can be pointed out to the user in what they typed.
+	// Only separators were removed, and they can't be in the charset, so the
+	// characters that are left are found in order in the input, and the end
+	// is the end of the input.
+	offsets := make([]int, 0, len(stripped)+1)
+	for i := 0; i < len(input); i++ {
+
+		if len(offsets) < len(stripped) && input[i] == stripped[len(offsets)] {
+
+			offsets = append(offsets, i)
+		}
+	}
+	offsets = append(offsets, len(input))
+
+	input = stripped
+	if normalised, e := normaliseCase(cdc, input); e == nil {
+
+		input = normalised
+	}
+
+	// Several different edits can produce the same string, for example
+	// swapping two identical characters or deleting either of a repeated pair,
+	// so each candidate string is only tried once.
+	tried := make(map[string]struct{})
+	try := func(m Mistake, pos int, found, expected, candidate string) {
+
+		if _, ok := tried[candidate]; ok {
+			return
+		}
+		tried[candidate] = struct{}{}
+
+		// With the first character omitted, a valid version 0 data part is
+		// always one short of a multiple of 8 characters, and later versions
+		// are a multiple of 8, anything else can't decode so there is no point
+		// trying it.
+		if l := (len(candidate) - len(cdc.HRP)) % 8; l != 0 && l != 7 {
+			return
+		}
+
+		if out, e := cdc.Decode(candidate); e == nil {
+
+			corrections = append(
+				corrections, Correction{
+					Mistake:   m,
+					Position:  offsets[pos],
+					Found:     found,
+					Expected:  expected,
+					Corrected: candidate,
+					Output:    out,
+				},
+			)
+		}
+	}
+
+	// The input itself must not be counted as a correction.
+	tried[input] = struct{}{}
+
+	start := len(cdc.HRP)
+	for i := start; i <= len(input); i++ {
+
+		head, tail := input[:i], input[i:]
+
+		// Insert each possible character before position i.
+		for j := range cdc.Charset {
+
+			c := cdc.Charset[j : j+1]
+			var found string
+			if len(tail) > 0 {
+
+				found = tail[:1]
+			}
+			try(Deletion, i, found, c, head+c+tail)
+		}
+
+		// The remaining edits all require a character at position i.
+		if len(tail) < 1 {
+			break
+		}
+
+		// Remove the character at position i.
+		try(Insertion, i, tail[:1], "", head+tail[1:])
+
+		// Replace the character at position i with each possible character.
+		for j := range cdc.Charset {
+
+			c := cdc.Charset[j : j+1]
+			if c == tail[:1] {
+				continue
+			}
+			try(Substitution, i, tail[:1], c, head+c+tail[1:])
+		}
+
+		// Swap the character at position i with the next one.
+		if len(tail) > 1 {
+
+			try(
+				Transposition, i, tail[:2], tail[1:2]+tail[:1],
+				head+tail[1:2]+tail[:1]+tail[2:],
+			)
+		}
+	}
+
+	sort.SliceStable(
+		corrections, func(i, j int) bool {
+			return corrections[i].Position < corrections[j].Position
+		},
+	)
+
+	return
+}
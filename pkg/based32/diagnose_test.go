@@ -0,0 +1,133 @@
+package based32
+
+import (
+	"bytes"
+	"lukechampine.com/blake3"
+	"strings"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+
+	// Use a hash chain to get a spread of inputs with each of the check
+	// lengths.
+	last := blake3.Sum256([]byte("diagnose"))
+	for i := 0; i < 10; i++ {
+
+		input := last[:len(last)-i%5]
+		last = blake3.Sum256(last[:])
+
+		encoded, err := Codec.Encode(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// A valid string needs no corrections.
+		output, corrections, err := Diagnose(Codec, encoded)
+		if err != nil || len(corrections) > 0 || !bytes.Equal(output, input) {
+			t.Fatalf("'%s' did not decode cleanly", encoded)
+		}
+
+		// Repeated characters make several positions equivalent for some of
+		// the mistakes, so pick a position with distinct neighbours, and a
+		// replacement different from all of them.
+		pos := len(Codec.HRP) + 3 + i
+		for encoded[pos-1] == encoded[pos] || encoded[pos] == encoded[pos+1] {
+
+			pos++
+		}
+		var other byte
+		for j := range charset {
+
+			if strings.IndexByte(encoded[pos-1:pos+2], charset[j]) < 0 {
+
+				other = charset[j]
+				break
+			}
+		}
+		mistakes := []struct {
+			mistake Mistake
+			typed   string
+		}{
+			{Substitution, encoded[:pos] + string(other) + encoded[pos+1:]},
+			{Insertion, encoded[:pos] + string(other) + encoded[pos:]},
+			{Deletion, encoded[:pos] + encoded[pos+1:]},
+			{
+				Transposition,
+				encoded[:pos] + encoded[pos+1:pos+2] + encoded[pos:pos+1] +
+					encoded[pos+2:],
+			},
+		}
+
+		for _, m := range mistakes {
+
+			if _, err = Codec.Decode(m.typed); err == nil {
+				t.Fatalf("%s '%s' was not detected", m.mistake, m.typed)
+			}
+
+			_, corrections, err = Diagnose(Codec, m.typed)
+			if err == nil {
+				t.Fatalf("%s '%s' returned no error", m.mistake, m.typed)
+			}
+
+			var found bool
+			for _, cor := range corrections {
+
+				if cor.Corrected == encoded && cor.Mistake == m.mistake {
+
+					if cor.Position != pos {
+						t.Fatalf(
+							"%s found at %d expected %d",
+							m.mistake, cor.Position, pos,
+						)
+					}
+					if !bytes.Equal(cor.Output, input) {
+						t.Fatalf("%s corrected output incorrect", m.mistake)
+					}
+					found = true
+				}
+			}
+			if !found {
+				t.Fatalf(
+					"%s '%s' not corrected to '%s', got %v",
+					m.mistake, m.typed, encoded, corrections,
+				)
+			}
+		}
+	}
+}
+
+func TestDiagnoseSeparators(t *testing.T) {
+
+	input := []byte("kitchensink")
+	encoded, err := Codec.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A substituted character after a separator is reported where it is in
+	// the string as it was typed.
+	pos := len(Codec.HRP) + 6
+	other := charset[(strings.IndexByte(charset, encoded[pos])+1)%32]
+	typed := encoded[:len(Codec.HRP)+4] + "-" + encoded[len(Codec.HRP)+4:pos] +
+		string(other) + encoded[pos+1:]
+
+	_, corrections, err := Diagnose(Codec, typed)
+	if err == nil {
+		t.Fatalf("'%s' returned no error", typed)
+	}
+	for _, cor := range corrections {
+
+		if cor.Corrected == encoded && cor.Mistake == Substitution {
+
+			if cor.Position != pos+1 || typed[cor.Position:cor.Position+1] !=
+				cor.Found {
+				t.Fatalf("found '%s' at %d expected '%c' at %d",
+					cor.Found, cor.Position, other, pos+1,
+				)
+			}
+			return
+		}
+	}
+	t.Fatalf("'%s' not corrected to '%s', got %v", typed, encoded, corrections)
+}
@@ -0,0 +1,119 @@
+package based32
+
+import (
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"strings"
+)
+
+// DecodeError is the error returned by the decoders of the package. As well as
+// the kind of error, which is one of the proto.Error codes, it says where in
+// the input the problem was found, so that a user interface can point it out.
+//
+// It matches its Kind with errors.Is, and errors.As can extract the Kind as a
+// proto.Error as well as extracting the DecodeError itself:
+//
+//	if errors.Is(err, proto.Error_CHECK_FAILED) {
+//		...
+//	}
+//
+//	var de *based32.DecodeError
+//	if errors.As(err, &de) && de.Offset >= 0 {
+//		...
+//	}
+type DecodeError struct {
+
+	// Kind is the error code, as sent by the gRPC service.
+	Kind proto.Error
+
+	// Offset is the byte offset into the input, counting the Human Readable
+	// Part and any separators, of the character or word that caused the
+	// error, or -1 if the error is not at any one place, such as for a check
+	// that fails.
+	Offset int
+
+	// ExpectedHRP and FoundHRP are the Human Readable Part of the codec and
+	// the start of the input in its place, for an
+	// INCORRECT_HUMAN_READABLE_PART error.
+	ExpectedHRP, FoundHRP string
+
+	// Err is the error that caused this one, if any.
+	Err error
+}
+
+// decodeError returns a DecodeError of the given kind at the offset.
+func decodeError(kind proto.Error, offset int) *DecodeError {
+
+	return &DecodeError{Kind: kind, Offset: offset}
+}
+
+// wrapDecodeError returns a DecodeError for an error from verify or an
+// encoder, which is not at any one place in the input. An error that is not
+// one of the proto.Error codes is kept as the cause, with the kind UNKNOWN.
+func wrapDecodeError(err error) (de *DecodeError) {
+
+	de = decodeError(proto.CodeOf(err), -1)
+	if _, ok := err.(proto.Error); !ok {
+
+		de.Err = err
+	}
+
+	return
+}
+
+// Error returns the name of the kind of error, with the details that are
+// known.
+func (e *DecodeError) Error() string {
+
+	var sb strings.Builder
+	sb.WriteString(e.Kind.Error())
+
+	if e.Offset >= 0 {
+
+		_, _ = fmt.Fprintf(&sb, " at offset %d", e.Offset)
+	}
+
+	if e.Kind == proto.Error_INCORRECT_HUMAN_READABLE_PART {
+
+		_, _ = fmt.Fprintf(
+			&sb, ": found '%s' expected '%s'", e.FoundHRP, e.ExpectedHRP,
+		)
+	}
+
+	if e.Err != nil {
+
+		sb.WriteString(": ")
+		sb.WriteString(e.Err.Error())
+	}
+
+	return sb.String()
+}
+
+// Unwrap returns the error that caused this one, if any.
+func (e *DecodeError) Unwrap() error {
+
+	return e.Err
+}
+
+// Is returns true if the target is the proto.Error of the same kind, so that
+// errors.Is works with the error codes as it did when they were returned
+// alone.
+func (e *DecodeError) Is(target error) bool {
+
+	kind, ok := target.(proto.Error)
+
+	return ok && kind == e.Kind
+}
+
+// As sets the target to the Kind if it is a *proto.Error, so that errors.As
+// can find the error code of any error from the decoders.
+func (e *DecodeError) As(target interface{}) bool {
+
+	kind, ok := target.(*proto.Error)
+	if ok {
+
+		*kind = e.Kind
+	}
+
+	return ok
+}
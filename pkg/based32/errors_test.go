@@ -0,0 +1,124 @@
+package based32
+
+import (
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecodeError(t *testing.T) {
+
+	encoded, err := Codec.Encode([]byte("kitchensink"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := encoded[len(Codec.HRP):]
+	grouped := Group(Codec, encoded, 4, "-")
+	words, err := Mnemonic.Encode([]byte("kitchensink"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := strings.Fields(words)
+
+	for _, c := range []struct {
+		cdc      func(string) ([]byte, error)
+		input    string
+		kind     proto.Error
+		offset   int
+		foundHRP string
+	}{
+		{Codec.Decode, "QNTRX" + data, proto.Error_INCORRECT_HUMAN_READABLE_PART,
+			4, "QNTRX"},
+		{Codec.Decode, "QN", proto.Error_INCORRECT_HUMAN_READABLE_PART, 2, "QN"},
+		{Codec.Decode, "QNTRL" + data[:3] + "b" + data[4:],
+			proto.Error_INVALID_CHARACTER, 8, ""},
+		{Codec.Decode, grouped[:11] + "b" + grouped[12:],
+			proto.Error_INVALID_CHARACTER, 11, ""},
+		{Codec.Decode, "QNTRL" + data[:5] + strings.ToUpper(data[5:]),
+			proto.Error_MIXED_CASE, 10, ""},
+		{Codec.Decode, encoded + "qq", proto.Error_INVALID_LENGTH, -1, ""},
+		{Codec.Decode, "QNTRL", proto.Error_ZERO_LENGTH, -1, ""},
+		{Codec.Decode, encoded[:len(encoded)-8] + "qqqqqqqq",
+			proto.Error_CHECK_FAILED, -1, ""},
+		{Mnemonic.Decode, strings.Replace(words, fields[2], "xyzzy", 1),
+			proto.Error_UNKNOWN_WORD, len(fields[0]) + len(fields[1]) + 2, ""},
+	} {
+
+		_, err := c.cdc(c.input)
+
+		var de *DecodeError
+		if !errors.As(err, &de) {
+			t.Fatalf("'%s' gave error %v of type %T", c.input, err, err)
+		}
+		if !errors.Is(err, c.kind) || de.Kind != c.kind {
+			t.Fatalf("'%s' gave error %v expected %v", c.input, err, c.kind)
+		}
+		if de.Offset != c.offset {
+			t.Fatalf("'%s' gave offset %d expected %d",
+				c.input, de.Offset, c.offset,
+			)
+		}
+		if c.kind == proto.Error_INCORRECT_HUMAN_READABLE_PART &&
+			(de.FoundHRP != c.foundHRP || de.ExpectedHRP != Codec.HRP) {
+			t.Fatalf("'%s' gave HRP '%s' expected '%s'",
+				c.input, de.FoundHRP, c.foundHRP,
+			)
+		}
+
+		// The error code can also be found directly.
+		var kind proto.Error
+		if !errors.As(err, &kind) || kind != c.kind ||
+			proto.CodeOf(err) != c.kind {
+			t.Fatalf("'%s' gave error code %v expected %v",
+				c.input, kind, c.kind,
+			)
+		}
+	}
+
+	// The cause is wrapped.
+	cause := errors.New("cause")
+	err = &DecodeError{Kind: proto.Error_CHECK_FAILED, Offset: -1, Err: cause}
+	if !errors.Is(err, cause) || errors.Is(err, proto.Error_ZERO_LENGTH) {
+		t.Fatalf("%v does not wrap %v", err, cause)
+	}
+	if err.Error() != "CHECK_FAILED: cause" {
+		t.Fatalf("got '%s'", err.Error())
+	}
+	// A character not in the charset is also the base32.CorruptInputError of
+	// the standard library decoder, at the same offset.
+	var corrupt base32.CorruptInputError
+	_, err = Codec.Decode(grouped[:11] + "b" + grouped[12:])
+	if !errors.As(err, &corrupt) || corrupt != 11 {
+		t.Fatalf("got %v", err)
+	}
+
+	// An error from elsewhere that is not a code is kept as the cause.
+	de := wrapDecodeError(io.ErrUnexpectedEOF)
+	if de.Kind != proto.Error_UNKNOWN || !errors.Is(de, io.ErrUnexpectedEOF) {
+		t.Fatalf("got %v", de)
+	}
+	if de = wrapDecodeError(proto.Error_CHECK_FAILED); de.Err != nil {
+		t.Fatalf("code kept as the cause of %v", de)
+	}
+
+	// Errors that are not codes are not mistaken for one, except by their
+	// text, as the codes were once only known by.
+	for _, c := range []struct {
+		err  error
+		kind proto.Error
+	}{
+		{io.ErrUnexpectedEOF, proto.Error_UNKNOWN},
+		{errors.New("CHECK_FAILED"), proto.Error_CHECK_FAILED},
+		{fmt.Errorf("wrapped: %w", proto.Error_INVALID_LENGTH),
+			proto.Error_INVALID_LENGTH},
+	} {
+
+		if kind := proto.CodeOf(c.err); kind != c.kind {
+			t.Fatalf("%v gave code %v expected %v", c.err, kind, c.kind)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package based32
+
+import (
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"strings"
+)
+
+// DefaultSeparators are the characters that are ignored when they appear
+// between the characters of the data part of a string being decoded. These are
+// what people and programs most often use to break up long codes: spaces and
+// hyphens, and the line breaks and tabs added by copying from documents and
+// terminals.
+const DefaultSeparators = " \t\r\n-"
+
+// Group splits the data part of an encoded string into groups of the given
+// size, separated by the separator, which is also placed between the Human
+// Readable Part and the first group, for example:
+//
+//	QNTRL-falg-en75-ph72-...
+//
+// This makes long codes much easier to read aloud and to copy by hand. The
+// result can be decoded directly by based32 codecs, which ignore separators.
+func Group(cdc *codec.Codec, encoded string, size int, separator string) (
+	grouped string,
+) {
+
+	if size < 1 || !hasHRP(cdc, encoded) {
+
+		return encoded
+	}
+
+	return string(groupInPlace(
+		[]byte(encoded), len(cdc.HRP), cdc.HRP != "", size, separator,
+	))
+}
+
+// groupSeparators returns how many separators grouping a data part of the
+// given length adds.
+func groupSeparators(dataLen int, hasHRP bool, size int) (count int) {
+
+	if dataLen < 1 {
+		return
+	}
+
+	count = (dataLen + size - 1) / size
+	if !hasHRP {
+
+		count--
+	}
+
+	return
+}
+
+// groupInPlace splits the data part of an encoded string, which starts at the
+// given offset in b, into groups as described for Group. The groups are moved
+// along within b to make room for the separators, so if b has enough spare
+// capacity nothing is allocated.
+func groupInPlace(b []byte, offset int, hasHRP bool, size int,
+	separator string,
+) (grouped []byte) {
+
+	dataLen := len(b) - offset
+	seps := groupSeparators(dataLen, hasHRP, size)
+	if seps < 1 {
+
+		return b
+	}
+
+	end := len(b)
+	grouped = grow(b, seps*len(separator))
+	grouped = grouped[:end+seps*len(separator)]
+
+	// Working from the end backwards means that no group is overwritten
+	// before it has been moved.
+	w := len(grouped)
+	for g := (dataLen - 1) / size; g >= 0; g-- {
+
+		from := offset + g*size
+		to := from + size
+		if to > end {
+
+			to = end
+		}
+
+		w -= to - from
+		copy(grouped[w:], grouped[from:to])
+
+		if g > 0 || hasHRP {
+
+			w -= len(separator)
+			copy(grouped[w:], separator)
+		}
+	}
+
+	return
+}
+
+// stripSeparators removes any of the separator characters from the data part
+// of the input, except those that are in the charset, as those are data. The
+// Human Readable Part is left as is, as it may contain the same characters.
+func stripSeparators(cdc *codec.Codec, input, separators string) (
+	output string,
+) {
+
+	if !hasHRP(cdc, input) {
+
+		return input
+	}
+
+	hrp, data := input[:len(cdc.HRP)], input[len(cdc.HRP):]
+
+	isSeparator := func(c byte) bool {
+
+		return strings.IndexByte(separators, c) >= 0 &&
+			strings.IndexByte(cdc.Charset, c) < 0
+	}
+
+	// Most input will have no separators, in which case there is no need to
+	// copy it.
+	found := false
+	for i := 0; i < len(data); i++ {
+
+		if isSeparator(data[i]) {
+
+			found = true
+			break
+		}
+	}
+
+	if !found {
+
+		return input
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(input))
+	sb.WriteString(hrp)
+
+	for i := 0; i < len(data); i++ {
+
+		if !isSeparator(data[i]) {
+
+			sb.WriteByte(data[i])
+		}
+	}
+
+	return sb.String()
+}
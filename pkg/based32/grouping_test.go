@@ -0,0 +1,70 @@
+package based32
+
+import (
+	"bytes"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"strings"
+	"testing"
+)
+
+func TestGrouping(t *testing.T) {
+
+	grouped, err := NewCodec(
+		"Grouped", charset, "QNTRL", WithGrouping(4, "-"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := []byte("a 32 byte long value, like hash")
+	plain, err := Codec.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var encoded string
+	if encoded, err = grouped.Encode(input); err != nil {
+		t.Fatal(err)
+	}
+	if encoded != Group(Codec, plain, 4, "-") {
+		t.Fatalf("grouped codec output '%s' differs from Group", encoded)
+	}
+
+	groups := strings.Split(encoded, "-")
+	if groups[0] != "QNTRL" {
+		t.Fatalf("first group '%s' is not the HRP", groups[0])
+	}
+	for _, g := range groups[1 : len(groups)-1] {
+
+		if len(g) != 4 {
+			t.Fatalf("group '%s' in '%s' is not 4 long", g, encoded)
+		}
+	}
+	if strings.Join(groups[1:], "") != plain[len(Codec.HRP):] {
+		t.Fatalf("'%s' does not contain the data of '%s'", encoded, plain)
+	}
+
+	// Both codecs must accept the plain and grouped forms, as well as the
+	// kind of mess that comes from copying codes out of emails.
+	messy := "QNTRL " + strings.Join(groups[1:], " - ")
+	messy = messy[:20] + "\r\n\t" + messy[20:]
+	for _, cdc := range []*codec.Codec{Codec, grouped} {
+
+		for _, s := range []string{plain, encoded, messy} {
+
+			var decoded []byte
+			if decoded, err = cdc.Decode(s); err != nil {
+				t.Fatalf("%s: '%s': %v", cdc.Name, s, err)
+			}
+			if !bytes.Equal(decoded, input) {
+				t.Fatalf("%s: got %x expected %x", cdc.Name, decoded, input)
+			}
+		}
+	}
+
+	if _, err = NewCodec(
+		"Bad", charset, "QNTRL", WithGrouping(4, "q"),
+	); err == nil {
+		t.Fatal("separator from the charset was accepted")
+	}
+}
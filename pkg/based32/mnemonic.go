@@ -0,0 +1,388 @@
+package based32
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Codes read aloud, such as over the phone, go much better as words than as
+// random characters, so as well as the base32 strings, the package can write
+// the same bytes, the header, the data and the check, as words from a fixed
+// list, in the manner of the BIP 0039 mnemonic phrases.
+//
+// Each word stands for 11 bits, so a word list has 2048 words. The bytes are
+// always a multiple of 5 long, so that the base32 encoding needs no padding,
+// and this also means that the length of the bytes can be found from the
+// number of words: it is the largest multiple of 5 bytes that the words hold.
+// The bits of the last word after the end of the bytes are zero.
+
+// english is the BIP 0039 English word list, one word per line.
+//
+//go:embed words/english.txt
+var english string
+
+// English is the BIP 0039 English word list, which is used by Mnemonic. Each
+// word can be identified by its first 4 letters.
+//
+// NewMnemonicCodec copies the list it is given, so changing this list does not
+// change any codec already created with it.
+var English = strings.Fields(english)
+
+const (
+
+	// WordListLen is the number of words that a word list must have.
+	WordListLen = 1 << wordBits
+
+	// wordBits is the number of bits each word stands for.
+	wordBits = 11
+)
+
+// Mnemonic is the word list counterpart of Codec. It has the same check
+// algorithm and format version, so the same data has the same check whichever
+// of the two it is written with.
+var Mnemonic = makeMnemonic("Base32CheckWords", English, defaultOptions())
+
+// validateWords checks that a word list has the right number of words, and
+// that the words can be told apart when decoding.
+func validateWords(words []string) (err error) {
+
+	if len(words) != WordListLen {
+
+		err = fmt.Errorf(
+			"word list must have %d words, got %d", WordListLen, len(words),
+		)
+		return
+	}
+
+	seen := make(map[string]int, len(words))
+	for i, word := range words {
+
+		if word == "" {
+
+			err = fmt.Errorf("word %d of the word list is empty", i)
+			return
+		}
+
+		// Words are separated by the DefaultSeparators and by white space,
+		// and decoded without regard to case, so words can't contain either,
+		// and must be in lower case so that they are written out in the same
+		// form as they are matched.
+		if strings.IndexFunc(word, isWordSeparator) >= 0 {
+
+			err = fmt.Errorf("word %d '%s' contains a separator", i, word)
+			return
+		}
+
+		if strings.ToLower(word) != word {
+
+			err = fmt.Errorf("word %d '%s' is not in lower case", i, word)
+			return
+		}
+
+		if j, ok := seen[word]; ok {
+
+			err = fmt.Errorf(
+				"word list contains '%s' at both %d and %d", word, j, i,
+			)
+			return
+		}
+		seen[word] = i
+	}
+
+	return
+}
+
+// isWordSeparator returns true for the characters that are found between
+// words.
+func isWordSeparator(r rune) bool {
+
+	return unicode.IsSpace(r) || strings.ContainsRune(DefaultSeparators, r)
+}
+
+// splitWords splits the input into words at the separators, returning the
+// byte offset of each word along with it.
+func splitWords(input string) (words []string, offsets []int) {
+
+	start := -1
+	for i, r := range input + " " {
+
+		switch {
+		case isWordSeparator(r) && start >= 0:
+			words = append(words, input[start:i])
+			offsets = append(offsets, start)
+			start = -1
+		case !isWordSeparator(r) && start < 0:
+			start = i
+		}
+	}
+
+	return
+}
+
+// NewMnemonicCodec creates a codec that writes data as words from the given
+// word list, with the default settings of the package Codec changed by the
+// given options.
+//
+// The word list must have WordListLen unique words in lower case, which
+// contain neither white space nor any of the DefaultSeparators. WithGrouping
+// can't be used, as the words are already separate, and WithUppercase writes
+// the words in upper case.
+//
+// The words are written separated by spaces. The decoder accepts any of the
+// DefaultSeparators and white space between them, in any case, and accepts the
+// start of a word in place of the whole word as long as no other word in the
+// list starts the same way. For the BIP 0039 lists the first 4 letters are
+// always enough.
+func NewMnemonicCodec(name string, words []string, opts ...Option) (
+	cdc *codec.Codec, err error,
+) {
+
+	if name == "" {
+
+		err = errors.New("codec name must not be empty")
+		return
+	}
+
+	if err = validateWords(words); err != nil {
+		return
+	}
+
+	o, err := applyOptions(opts)
+	if err != nil {
+		return
+	}
+
+	if o.groupSize > 0 {
+
+		err = errors.New("grouping can't be used with a word list")
+		return
+	}
+
+	cdc = makeMnemonic(name, words, o)
+
+	return
+}
+
+// makeMnemonic creates a codec that writes data as words from the word list.
+func makeMnemonic(name string, words []string, o options) (cdc *codec.Codec) {
+
+	// The words take the place of both the charset and the HRP, so these are
+	// left empty.
+	cdc = &codec.Codec{
+		Name:      name,
+		CheckName: o.checksum.String(),
+		MakeCheck: makeCheck(o.check),
+	}
+
+	// The list is copied, in the case the output is written in, so the caller
+	// can't change the words of the codec afterwards.
+	list := make([]string, len(words))
+	for i, word := range words {
+
+		list[i] = word
+		if o.uppercase {
+
+			list[i] = strings.ToUpper(word)
+		}
+	}
+
+	// Words are looked up by a map when given in full, and by searching a
+	// sorted copy of the list when only the start is given, as the words that
+	// start the same way are next to each other in sorted order.
+	index := make(map[string]int, len(words))
+	for i, word := range words {
+
+		index[word] = i
+	}
+	sorted := make([]string, len(words))
+	copy(sorted, words)
+	sort.Strings(sorted)
+
+	lookup := func(word string) (value int, err error) {
+
+		word = strings.ToLower(word)
+		var ok bool
+		if value, ok = index[word]; ok {
+			return
+		}
+
+		i := sort.SearchStrings(sorted, word)
+		switch {
+		case i == len(sorted) || !strings.HasPrefix(sorted[i], word):
+
+			err = proto.Error_UNKNOWN_WORD
+
+		case i+1 < len(sorted) && strings.HasPrefix(sorted[i+1], word):
+
+			err = proto.Error_AMBIGUOUS_WORD
+
+		default:
+
+			value = index[sorted[i]]
+		}
+
+		return
+	}
+
+	cdc.AppendEncoder = func(dst, input []byte) (output []byte, err error) {
+
+		if len(input) < 1 {
+
+			return dst, proto.Error_ZERO_LENGTH
+		}
+
+		payload, compressed := o.compress(input)
+		raw := appendRaw(nil, payload, compressed, o)
+
+		// The bits are taken from the bytes 11 at a time, most significant
+		// first, and the last word is filled up with zeros.
+		output = dst
+		var bits uint32
+		var n int
+		for i := 0; i < len(raw) || n > 0; {
+
+			if n < wordBits && i < len(raw) {
+
+				bits = bits<<8 | uint32(raw[i])
+				n += 8
+				i++
+				continue
+			}
+
+			var value uint32
+			if n >= wordBits {
+
+				value = bits >> (n - wordBits)
+				n -= wordBits
+			} else {
+
+				value = bits << (wordBits - n)
+				n = 0
+			}
+			bits &= 1<<n - 1
+
+			if len(output) > len(dst) {
+
+				output = append(output, ' ')
+			}
+			output = append(output, list[value&(WordListLen-1)]...)
+		}
+
+		return
+	}
+
+	cdc.Encoder = func(input []byte) (output string, err error) {
+
+		var b []byte
+		if b, err = cdc.AppendEncoder(nil, input); err != nil {
+			return
+		}
+
+		return string(b), nil
+	}
+
+	cdc.Check = func(input []byte) (err error) {
+
+		return verify(input, o)
+	}
+
+	cdc.AppendDecoder = func(dst, input []byte) (output []byte, err error) {
+
+		fields, offsets := splitWords(string(input))
+		if len(fields) < 1 {
+
+			return dst, decodeError(proto.Error_ZERO_LENGTH, -1)
+		}
+
+		// The bytes are the largest multiple of 5 that the words hold, and
+		// there must not be any more words than are needed for them.
+		rawLen := len(fields) * wordBits / 8 / 5 * 5
+		if rawLen < 1 || (rawLen*8+wordBits-1)/wordBits != len(fields) {
+
+			return dst, decodeError(proto.Error_INVALID_LENGTH, -1)
+		}
+
+		raw := make([]byte, 0, (len(fields)*wordBits+7)/8)
+		var bits uint32
+		var n int
+		for i, field := range fields {
+
+			var value int
+			if value, err = lookup(field); err != nil {
+
+				kind, _ := err.(proto.Error)
+				return dst, decodeError(kind, offsets[i])
+			}
+
+			bits = bits<<wordBits | uint32(value)
+			n += wordBits
+			for n >= 8 {
+
+				raw = append(raw, byte(bits>>(n-8)))
+				n -= 8
+			}
+			bits &= 1<<n - 1
+		}
+
+		// Anything after the bytes must be the zeros that filled up the last
+		// word, or there would be more than one way to write the same bytes.
+		last := offsets[len(offsets)-1]
+		if bits != 0 {
+
+			return dst, decodeError(proto.Error_INVALID_PADDING, last)
+		}
+		for _, b := range raw[rawLen:] {
+
+			if b != 0 {
+
+				return dst, decodeError(proto.Error_INVALID_PADDING, last)
+			}
+		}
+		raw = raw[:rawLen]
+
+		// The check also rejects versions that can't be decoded.
+		_, checkLen, compressed := parseHeader(raw[0])
+		if err = cdc.Check(raw); err != nil {
+
+			return dst, wrapDecodeError(err)
+		}
+
+		payload := raw[1:getCutPoint(len(raw), checkLen)]
+		if compressed {
+
+			if output, err = o.decompress(dst, payload); err != nil {
+
+				return dst, err
+			}
+		} else {
+
+			output = append(dst, payload...)
+		}
+
+		if o.strict {
+
+			err = canonical(
+				cdc, o, input, output[len(dst):], len(payload), checkLen,
+			)
+			if err != nil {
+
+				return dst, err
+			}
+		}
+
+		return
+	}
+
+	cdc.Decoder = func(input string) (output []byte, err error) {
+
+		return cdc.AppendDecoder(nil, []byte(input))
+	}
+
+	return cdc
+}
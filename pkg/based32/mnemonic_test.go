@@ -0,0 +1,292 @@
+package based32
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestEnglish(t *testing.T) {
+
+	if err := validateWords(English); err != nil {
+		t.Fatal(err)
+	}
+	if English[0] != "abandon" || English[WordListLen-1] != "zoo" {
+		t.Fatalf("got first word %s last word %s", English[0], English[2047])
+	}
+}
+
+func TestMnemonic(t *testing.T) {
+
+	v1, err := NewMnemonicCodec(
+		"Words1", English, WithVersion(Version1), WithChecksum(CRC32C),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cdc := range []struct {
+		mnemonic, based32 func(input []byte) (string, error)
+		o                 options
+	}{
+		{Mnemonic.Encode, Codec.Encode, defaultOptions()},
+		{v1.Encode, nil, options{
+			checksum: CRC32C, check: crc32cCheck, version: Version1,
+		}},
+	} {
+
+		for l := 1; l <= 64; l++ {
+
+			input := make([]byte, l)
+			rand.Read(input)
+
+			words, err := cdc.mnemonic(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			raw := appendRaw(nil, input, false, cdc.o)
+			if n := len(strings.Fields(words)); n != (len(raw)*8+10)/11 {
+				t.Fatalf("%d bytes gave %d words", len(raw), n)
+			}
+
+			decoded, err := Mnemonic.Decode(words)
+			if cdc.o.version == Version1 {
+				decoded, err = v1.Decode(words)
+			}
+			if err != nil {
+				t.Fatalf("decoding '%s': %v", words, err)
+			}
+			if !bytes.Equal(decoded, input) {
+				t.Fatalf("got %x expected %x", decoded, input)
+			}
+
+			// The words hold the same bytes as the based32 string of the same
+			// data, which is the header and data followed by the check.
+			if cdc.based32 == nil {
+				continue
+			}
+			encoded, err := cdc.based32(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := []byte("q" + encoded[len(Codec.HRP):])
+			fromString := make([]byte, len(text)*5/8)
+			if _, err = decode(fromString, text, &charsetValues); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(fromString, raw) {
+				t.Fatalf("string has bytes %x words have %x", fromString, raw)
+			}
+		}
+	}
+}
+
+// charsetValues is the table of the values of the characters of charset.
+var charsetValues = func() (values [256]byte) {
+
+	for i := range values {
+
+		values[i] = invalidChar
+	}
+	for i := 0; i < len(charset); i++ {
+
+		values[charset[i]] = byte(i)
+	}
+
+	return
+}()
+
+func TestMnemonicDecode(t *testing.T) {
+
+	input := []byte("kitchensink")
+	words, err := Mnemonic.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := strings.Fields(words)
+
+	// The first 4 letters of any of the English words are enough, and the
+	// words can be in any case with any separators between them.
+	var short []string
+	for _, word := range fields {
+
+		if len(word) > 4 {
+
+			word = word[:4]
+		}
+		short = append(short, word)
+	}
+
+	for _, variant := range []string{
+		strings.Join(short, " "),
+		strings.ToUpper(words),
+		strings.Join(fields, "-"),
+		"  " + strings.Join(fields, "\n") + "\n",
+		strings.Join(fields[:3], "\t") + "\r\n" + strings.Join(fields[3:], " "),
+	} {
+
+		decoded, err := Mnemonic.Decode(variant)
+		if err != nil {
+			t.Fatalf("decoding '%s': %v", variant, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Fatalf("'%s' gave %x expected %x", variant, decoded, input)
+		}
+	}
+
+	// A word ending in zeros, which is "abandon", added to the end makes too
+	// many words, and the filling of the last word must be zero.
+	last := fields[len(fields)-1]
+	next := English[(indexOf(last)+1)%WordListLen]
+	for _, c := range []struct {
+		words string
+		err   error
+	}{
+		{"", proto.Error_ZERO_LENGTH},
+		{" - ", proto.Error_ZERO_LENGTH},
+		{"abandon", proto.Error_INVALID_LENGTH},
+		{words + " abandon", proto.Error_INVALID_LENGTH},
+		{strings.Join(fields[1:], " "), proto.Error_INVALID_LENGTH},
+		{strings.Replace(words, fields[1], "kitchensink", 1),
+			proto.Error_UNKNOWN_WORD},
+		{strings.Replace(words, fields[1], "ab", 1), proto.Error_AMBIGUOUS_WORD},
+		{strings.Join(append(fields[:len(fields)-1:len(fields)-1], next), " "),
+			proto.Error_INVALID_PADDING},
+		{strings.Replace(words, fields[2], English[indexOf(fields[2])^1], 1),
+			proto.Error_CHECK_FAILED},
+	} {
+
+		if _, err := Mnemonic.Decode(c.words); !errors.Is(err, c.err) {
+			t.Fatalf("'%s' gave error %v expected %v", c.words, err, c.err)
+		}
+	}
+}
+
+// indexOf returns the index of a word in the English list.
+func indexOf(word string) int {
+
+	for i, w := range English {
+
+		if w == word {
+
+			return i
+		}
+	}
+
+	return -1
+}
+
+func TestNewMnemonicCodec(t *testing.T) {
+
+	// A made up list, in which "word1" is also the start of "word10" and so
+	// on, which are only accepted in full.
+	words := make([]string, WordListLen)
+	for i := range words {
+
+		words[i] = fmt.Sprintf("word%d", i)
+	}
+
+	cdc, err := NewMnemonicCodec("Numbered", words, WithUppercase())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := []byte{1, 2, 3, 4, 5, 6, 7}
+	encoded, err := cdc.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, "WORD") {
+		t.Fatalf("got '%s' expected upper case words", encoded)
+	}
+	decoded, err := cdc.Decode(strings.ToLower(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Fatalf("got %x expected %x", decoded, input)
+	}
+	if _, err = cdc.Decode("word1 " + encoded); !errors.Is(
+		err, proto.Error_INVALID_LENGTH,
+	) {
+		t.Fatalf("got error %v", err)
+	}
+
+	// Changing the list afterwards doesn't change the codec.
+	words[0] = "changed"
+	if again, _ := cdc.Encode(input); again != encoded {
+		t.Fatalf("got '%s' expected '%s'", again, encoded)
+	}
+	words[0] = "word0"
+
+	duplicate := append([]string{}, words...)
+	duplicate[5] = "word6"
+	upper := append([]string{}, words...)
+	upper[5] = "Word5"
+	spaced := append([]string{}, words...)
+	spaced[5] = "word 5"
+	hyphen := append([]string{}, words...)
+	hyphen[5] = "word-5"
+	empty := append([]string{}, words...)
+	empty[5] = ""
+
+	for _, c := range []struct {
+		name  string
+		words []string
+		opts  []Option
+	}{
+		{"", words, nil},
+		{"Short", words[1:], nil},
+		{"Duplicate", duplicate, nil},
+		{"Upper", upper, nil},
+		{"Spaced", spaced, nil},
+		{"Hyphen", hyphen, nil},
+		{"Empty", empty, nil},
+		{"Grouped", words, []Option{WithGrouping(4, "-")}},
+		{"Version", words, []Option{WithVersion(LatestVersion + 1)}},
+	} {
+
+		if _, err = NewMnemonicCodec(c.name, c.words, c.opts...); err == nil {
+			t.Fatalf("%s word list gave no error", c.name)
+		}
+	}
+}
+
+func TestMnemonicStrict(t *testing.T) {
+
+	plain, err := NewMnemonicCodec("Words", English, WithVersion(Version1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	strict, err := NewMnemonicCodec("Strict", English,
+		WithVersion(Version1), WithCompression(), WithStrict(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := repetitive(1024)
+	encoded, err := strict.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = strict.Decode(encoded); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same data not compressed has a check of a different length, but it
+	// is the payload that is not what the encoder makes.
+	if encoded, err = plain.Encode(input); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = strict.Decode(encoded); !errors.Is(
+		err, proto.Error_NON_CANONICAL,
+	) {
+		t.Fatalf("got error %v", err)
+	}
+}
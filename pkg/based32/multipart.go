@@ -0,0 +1,289 @@
+package based32
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"lukechampine.com/blake3"
+)
+
+// Payloads too long to be written down or scanned as one string can be split
+// into parts, each of which is a separate based32 string, in the manner of the
+// animated QR codes that show one part after another. The parts can be joined
+// back together in any order.
+//
+// Before encoding, each part is prefixed with its index and the total number of
+// parts, both as unsigned varints, and the digest of the whole payload, which
+// identifies the message the part belongs to and verifies the payload once it
+// has been joined back together.
+const (
+
+	// DefaultPartSize is the most payload bytes put in each part by Split,
+	// which keeps each part about as long as a 32 byte hash.
+	DefaultPartSize = 24
+
+	// MaxParts is the most parts a payload can be split into.
+	MaxParts = 1 << 16
+
+	// partDigestLen is the length of the digest of the whole payload carried by
+	// each part, which is the Blake3 hash truncated. It is long enough that the
+	// parts of two different messages practically never have the same digest
+	// and get joined together.
+	partDigestLen = 8
+)
+
+var (
+
+	// ErrPartHeader is returned when a part is too short to hold the index,
+	// total and digest.
+	ErrPartHeader = errors.New("part is too short for its header")
+
+	// ErrPartIndex is returned when a part has an index that is not less than
+	// its total, or a total of zero or more than MaxParts.
+	ErrPartIndex = errors.New("part index out of range")
+
+	// ErrPartMismatch is returned by a Joiner when a part has a different total
+	// or digest to the parts already added, which means it is a part of a
+	// different message.
+	ErrPartMismatch = errors.New("part belongs to a different message")
+
+	// ErrDuplicatePart is returned by a Joiner when the same part has already
+	// been added. The Joiner is not changed, so when parts are scanned over and
+	// over, as with a phone held up to a screen, this can be ignored, or used
+	// to tell the user to move on to the next part.
+	ErrDuplicatePart = errors.New("part already added")
+
+	// ErrConflictingPart is returned by a Joiner when a part with the same
+	// index but different content has already been added, which can't happen
+	// unless one of them has been tampered with or the digest has matched
+	// another message's by chance.
+	ErrConflictingPart = errors.New("part already added with different content")
+
+	// ErrMissingParts is returned when joining before all of the parts have
+	// been added.
+	ErrMissingParts = errors.New("parts are missing")
+
+	// ErrPartDigest is returned when the joined payload does not match the
+	// digest carried by the parts.
+	ErrPartDigest = errors.New("joined parts do not match digest")
+)
+
+// Split splits the payload into parts of at most partSize bytes and encodes
+// them with the package Codec.
+func Split(payload []byte, partSize int) (parts []string, err error) {
+
+	return SplitCodec(Codec, payload, partSize)
+}
+
+// SplitCodec splits the payload into parts of at most partSize bytes and
+// encodes them with the given codec. The payload is shared out evenly, so the
+// parts are all the same length give or take one byte.
+func SplitCodec(cdc *codec.Codec, payload []byte, partSize int) (
+	parts []string, err error,
+) {
+
+	if len(payload) < 1 {
+
+		err = proto.Error_ZERO_LENGTH
+		return
+	}
+
+	if partSize < 1 {
+
+		err = fmt.Errorf("part size must be at least 1, got %d", partSize)
+		return
+	}
+
+	total := (len(payload) + partSize - 1) / partSize
+	if total > MaxParts {
+
+		err = fmt.Errorf(
+			"%d bytes in parts of %d bytes is more than %d parts",
+			len(payload), partSize, MaxParts,
+		)
+		return
+	}
+
+	digest := blake3.Sum256(payload)
+
+	for i := 0; i < total; i++ {
+
+		// Each part gets its share of the payload, with some getting one more
+		// byte than others if it doesn't divide evenly.
+		from := i * len(payload) / total
+		to := (i + 1) * len(payload) / total
+
+		part := appendUvarint(nil, uint64(i))
+		part = appendUvarint(part, uint64(total))
+		part = append(part, digest[:partDigestLen]...)
+		part = append(part, payload[from:to]...)
+
+		var s string
+		if s, err = cdc.Encode(part); err != nil {
+
+			return nil, err
+		}
+		parts = append(parts, s)
+	}
+
+	return
+}
+
+// Join decodes the parts, given in any order, with the package Codec and
+// returns the payload they were split from.
+func Join(parts []string) (payload []byte, err error) {
+
+	return JoinCodec(Codec, parts)
+}
+
+// JoinCodec decodes the parts, given in any order, with the given codec and
+// returns the payload they were split from. Each part must be given only once,
+// so a part that is repeated gives ErrDuplicatePart.
+func JoinCodec(cdc *codec.Codec, parts []string) (payload []byte, err error) {
+
+	j := NewCodecJoiner(cdc)
+	for _, part := range parts {
+
+		if err = j.Add(part); err != nil {
+			return
+		}
+	}
+
+	return j.Payload()
+}
+
+// Joiner collects parts, as they are scanned or typed in, until it has all of
+// them, and then returns the payload.
+type Joiner struct {
+	cdc    *codec.Codec
+	total  int
+	digest []byte
+	parts  [][]byte
+	have   int
+}
+
+// NewJoiner creates a Joiner that decodes parts with the package Codec.
+func NewJoiner() (j *Joiner) {
+
+	return NewCodecJoiner(Codec)
+}
+
+// NewCodecJoiner creates a Joiner that decodes parts with the given codec.
+func NewCodecJoiner(cdc *codec.Codec) (j *Joiner) {
+
+	return &Joiner{cdc: cdc}
+}
+
+// Add decodes a part and adds it to those collected. The first part added
+// decides which message the Joiner is collecting the parts of. A part that has
+// already been added gives ErrDuplicatePart, and leaves the Joiner as it was.
+func (j *Joiner) Add(part string) (err error) {
+
+	data, err := j.cdc.Decode(part)
+	if err != nil {
+		return
+	}
+
+	index, n := binary.Uvarint(data)
+	if n <= 0 {
+
+		return ErrPartHeader
+	}
+	data = data[n:]
+
+	total, n := binary.Uvarint(data)
+	if n <= 0 || len(data)-n < partDigestLen {
+
+		return ErrPartHeader
+	}
+	data = data[n:]
+
+	if total == 0 || total > MaxParts || index >= total {
+
+		return fmt.Errorf("%w: part %d of %d", ErrPartIndex, index, total)
+	}
+
+	digest, fragment := data[:partDigestLen], data[partDigestLen:]
+
+	// The first part sets up the Joiner.
+	if j.parts == nil {
+
+		j.total, j.digest = int(total), digest
+		j.parts = make([][]byte, total)
+	}
+
+	if int(total) != j.total || string(digest) != string(j.digest) {
+
+		return ErrPartMismatch
+	}
+
+	if j.parts[index] != nil {
+
+		if string(j.parts[index]) != string(fragment) {
+
+			return fmt.Errorf("%w: part %d", ErrConflictingPart, index)
+		}
+
+		return fmt.Errorf("%w: part %d", ErrDuplicatePart, index)
+	}
+
+	j.parts[index] = fragment
+	j.have++
+
+	return
+}
+
+// Total returns the number of parts of the message, or zero if no part has
+// been added yet.
+func (j *Joiner) Total() int { return j.total }
+
+// Complete returns true when all of the parts have been added.
+func (j *Joiner) Complete() bool { return j.total > 0 && j.have == j.total }
+
+// Missing returns the indexes of the parts that have not been added yet, which
+// is nil if no part has been added, as the total is not known.
+func (j *Joiner) Missing() (indexes []int) {
+
+	for i, part := range j.parts {
+
+		if part == nil {
+
+			indexes = append(indexes, i)
+		}
+	}
+
+	return
+}
+
+// Payload joins the parts and returns the payload, once all of the parts have
+// been added.
+func (j *Joiner) Payload() (payload []byte, err error) {
+
+	if !j.Complete() {
+
+		err = fmt.Errorf("%w: %v", ErrMissingParts, j.Missing())
+		return
+	}
+
+	for _, part := range j.parts {
+
+		payload = append(payload, part...)
+	}
+
+	if digest := blake3.Sum256(payload); string(digest[:partDigestLen]) !=
+		string(j.digest) {
+
+		return nil, ErrPartDigest
+	}
+
+	return
+}
+
+// appendUvarint appends the unsigned varint encoding of u to the data.
+func appendUvarint(data []byte, u uint64) []byte {
+
+	var buf [binary.MaxVarintLen64]byte
+	return append(data, buf[:binary.PutUvarint(buf[:], u)]...)
+}
@@ -0,0 +1,132 @@
+package based32
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestMultipart(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(3))
+	payload := make([]byte, 100)
+	rng.Read(payload)
+
+	parts, err := Split(payload, DefaultPartSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 5 {
+		t.Fatalf("got %d parts expected 5", len(parts))
+	}
+
+	// The parts can be joined in any order.
+	rng.Shuffle(len(parts), func(i, j int) {
+		parts[i], parts[j] = parts[j], parts[i]
+	})
+	joined, err := Join(parts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(joined, payload) {
+		t.Fatalf("got %x expected %x", joined, payload)
+	}
+
+	// A Joiner reports what is missing, and tells a repeated part apart from
+	// a different part with the same index.
+	j := NewJoiner()
+	if missing := j.Missing(); missing != nil {
+		t.Fatalf("got missing %v before any parts", missing)
+	}
+	if err = j.Add(parts[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err = j.Add(parts[0]); !errors.Is(err, ErrDuplicatePart) {
+		t.Fatalf("repeated part gave error %v", err)
+	}
+	changed, err := Codec.Decode(parts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed[len(changed)-1] ^= 1
+	tampered, err := Codec.Encode(changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = j.Add(tampered); !errors.Is(err, ErrConflictingPart) {
+		t.Fatalf("different part with the same index gave error %v", err)
+	}
+	if _, err = j.Payload(); !errors.Is(err, ErrMissingParts) {
+		t.Fatalf("incomplete parts gave error %v", err)
+	}
+	if j.Total() != 5 || len(j.Missing()) != 4 || j.Complete() {
+		t.Fatalf("got total %d missing %v", j.Total(), j.Missing())
+	}
+	for _, part := range parts[1:] {
+
+		if err = j.Add(part); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !j.Complete() || j.Missing() != nil {
+		t.Fatalf("got missing %v", j.Missing())
+	}
+
+	// Parts of another message are rejected.
+	other, err := Split(payload[1:], DefaultPartSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = j.Add(other[0]); !errors.Is(err, ErrPartMismatch) {
+		t.Fatalf("part of another message gave error %v", err)
+	}
+
+	if _, err = Join(parts[1:]); !errors.Is(err, ErrMissingParts) {
+		t.Fatalf("missing part gave error %v", err)
+	}
+	if _, err = Join(append(parts, parts[2])); !errors.Is(
+		err, ErrDuplicatePart,
+	) {
+		t.Fatalf("repeated part gave error %v", err)
+	}
+
+	// A short payload is a single part.
+	if parts, err = Split(payload[:10], DefaultPartSize); err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 1 {
+		t.Fatalf("got %d parts expected 1", len(parts))
+	}
+
+	// Parts with an index out of range, or too short for their header.
+	for _, raw := range [][]byte{
+		{5, 5, 1, 2, 3, 4, 5, 6, 7, 8, 0},
+		{0, 0, 1, 2, 3, 4, 5, 6, 7, 8, 0},
+		{0, 1, 1, 2, 3, 4, 5, 6, 7},
+		{0, 1, 2},
+	} {
+
+		encoded, err := Codec.Encode(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = NewJoiner().Add(encoded); !errors.Is(err, ErrPartIndex) &&
+			!errors.Is(err, ErrPartHeader) {
+			t.Fatalf("%x gave error %v", raw, err)
+		}
+	}
+
+	// A part with a digest that doesn't match the payload.
+	encoded, err := Codec.Encode([]byte{0, 1, 1, 2, 3, 4, 5, 6, 7, 8, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Join([]string{encoded}); !errors.Is(err, ErrPartDigest) {
+		t.Fatalf("wrong digest gave error %v", err)
+	}
+
+	if _, err = Split(nil, DefaultPartSize); err == nil {
+		t.Fatal("empty payload gave no error")
+	}
+}
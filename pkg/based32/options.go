@@ -0,0 +1,399 @@
+package based32
+
+import (
+	"errors"
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"strings"
+)
+
+// options collects the settings of a codec created by NewCodec. It is not
+// exported so that the only way to change it is through the Option functions,
+// which can validate what they are given.
+type options struct {
+	checksum       Checksum
+	check          checkFunc
+	groupSize      int
+	groupSeparator string
+	uppercase      bool
+	version        int
+	versionSet     bool
+	minCheckLen    int
+	strict         bool
+
+	compression     bool
+	maxDecompressed int
+}
+
+// defaultOptions returns the settings used for the package Codec.
+func defaultOptions() options {
+
+	return options{
+		checksum:        Blake3,
+		check:           blake3Check,
+		minCheckLen:     1,
+		maxDecompressed: DefaultMaxDecompressedSize,
+	}
+}
+
+// checkLen returns the number of check bytes for an input of the given length.
+// It is the length from getCheckLen, which avoids padding, made longer by
+// multiples of 5 bytes, which keep it from needing padding, until it is at
+// least the minimum.
+func (o options) checkLen(length int) (checkLen int) {
+
+	for checkLen = getCheckLen(length); checkLen < o.minCheckLen; {
+
+		checkLen += 5
+	}
+
+	return
+}
+
+// Option is a function that changes a setting of a codec created by NewCodec.
+//
+// This is known as the "functional options" pattern. It allows a constructor
+// to have any number of optional settings without a long parameter list full
+// of zero values, and new options can be added later without changing the
+// signature of the constructor and breaking existing callers.
+type Option func(o *options) (err error)
+
+// WithChecksum selects the algorithm used to create the check bytes. Use
+// WithKeyedBlake3 for KeyedBlake3 as it needs a key.
+func WithChecksum(checksum Checksum) Option {
+
+	return func(o *options) (err error) {
+
+		switch checksum {
+		case Blake3:
+			o.check = blake3Check
+		case SHA256:
+			o.check = sha256Check
+		case CRC32C:
+			o.check = crc32cCheck
+		case KeyedBlake3:
+			err = errors.New("the KeyedBlake3 checksum requires WithKeyedBlake3")
+			return
+		default:
+			err = fmt.Errorf("unknown checksum algorithm %d", checksum)
+			return
+		}
+
+		o.checksum = checksum
+
+		return
+	}
+}
+
+// WithKeyedBlake3 selects the Blake3 keyed hash as the check algorithm, with
+// the given key, which must be 32 bytes long.
+func WithKeyedBlake3(key []byte) Option {
+
+	return func(o *options) (err error) {
+
+		if len(key) != 32 {
+
+			err = fmt.Errorf(
+				"keyed Blake3 requires a 32 byte key, got %d bytes", len(key),
+			)
+			return
+		}
+
+		o.checksum = KeyedBlake3
+		o.check = keyedBlake3Check(key)
+
+		return
+	}
+}
+
+// WithGrouping makes the encoder split the data part of its output into groups
+// of the given size with the separator between them, as described for Group.
+// The separator must not contain any characters of the charset.
+//
+// Codecs always accept grouped input regardless of this option, and ignore the
+// separator given here as well as the DefaultSeparators.
+func WithGrouping(size int, separator string) Option {
+
+	return func(o *options) (err error) {
+
+		if size < 1 {
+
+			err = fmt.Errorf("group size must be at least 1, got %d", size)
+			return
+		}
+
+		if separator == "" {
+
+			err = errors.New("group separator must not be empty")
+			return
+		}
+
+		o.groupSize, o.groupSeparator = size, separator
+
+		return
+	}
+}
+
+// WithUppercase makes the encoder produce entirely upper case output, which QR
+// codes can store in their compact alphanumeric mode. The charset must not
+// have letters of both cases.
+//
+// Codecs with a charset of one case always accept data parts that are either
+// entirely upper or lower case regardless of this option.
+func WithUppercase() Option {
+
+	return func(o *options) (err error) {
+
+		o.uppercase = true
+		return
+	}
+}
+
+// WithVersion selects the format version produced by the encoder. The default
+// is Version0, the original format, so existing codes are unchanged. Codecs
+// always decode every version up to LatestVersion regardless of this option.
+func WithVersion(version int) Option {
+
+	return func(o *options) (err error) {
+
+		if version < Version0 || version > LatestVersion {
+
+			err = fmt.Errorf(
+				"format version %d is not between %d and %d",
+				version, Version0, LatestVersion,
+			)
+			return
+		}
+
+		o.version, o.versionSet = version, true
+
+		return
+	}
+}
+
+// WithMinCheckBits sets the minimum strength of the check, in bits, which is
+// rounded up to whole bytes. Without it, some lengths of input get only 2 bytes
+// of check, which a random string passes once in 65536 tries.
+//
+// The encoder makes the check longer for those lengths, by 5 bytes at a time
+// so that the encoding still needs no padding, and the decoder rejects codes
+// with a shorter check with CHECK_TOO_SHORT. The check length has to fit in
+// the header, so the most that can be asked for is 24 bits with Version0 and
+// 32 bits with Version1. Codecs asking for more than 24 bits use Version1 if
+// no version is given, and can't be created with WithVersion(Version0).
+func WithMinCheckBits(bits int) Option {
+
+	return func(o *options) (err error) {
+
+		if bits < 1 || bits > maxMinCheckLen(LatestVersion)*8 {
+
+			err = fmt.Errorf(
+				"minimum check bits must be between 1 and %d, got %d",
+				maxMinCheckLen(LatestVersion)*8, bits,
+			)
+			return
+		}
+
+		o.minCheckLen = (bits + 7) / 8
+
+		return
+	}
+}
+
+// WithStrict makes the decoder accept only the exact strings that the encoder
+// of the codec produces, so that no two different strings decode to the same
+// data, as is needed when the strings are stored as identifiers.
+//
+// Without it, the decoder accepts any separators and either case, and any
+// check length and format version that the check passes with. In strict mode,
+// a check length other than the one the encoder would choose for the length
+// of the data is rejected with INVALID_CHECK_LENGTH, and any other difference
+// from the encoding of the data, including the separators, case and version,
+// with NON_CANONICAL.
+func WithStrict() Option {
+
+	return func(o *options) (err error) {
+
+		o.strict = true
+		return
+	}
+}
+
+// validateOptions checks that the settings chosen by the options work
+// together, as they can be given in any order.
+func validateOptions(o options) (err error) {
+
+	if o.minCheckLen > maxMinCheckLen(o.version) {
+
+		err = fmt.Errorf(
+			"a minimum check of %d bytes can't be encoded in version %d, "+
+				"which allows at most %d",
+			o.minCheckLen, o.version, maxMinCheckLen(o.version),
+		)
+		return
+	}
+
+	if o.compression && o.version == Version0 {
+
+		err = errors.New(
+			"compression needs version 1 or later, as version 0 has no " +
+				"compression flag",
+		)
+	}
+
+	return
+}
+
+// validate checks that a name, charset and Human Readable Part will make a
+// codec that works correctly.
+func validate(name, cs, hrp string) (err error) {
+
+	if name == "" {
+
+		err = errors.New("codec name must not be empty")
+		return
+	}
+
+	// The standard library base32 encoder panics if the charset is not 32
+	// bytes long, and the characters must all be different or two values will
+	// encode the same.
+	if len(cs) != 32 {
+
+		err = fmt.Errorf(
+			"charset must be 32 characters long, '%s' is %d", cs, len(cs),
+		)
+		return
+	}
+
+	for i := 0; i < len(cs); i++ {
+
+		// Whitespace can't be used as it is stripped by so many things, and
+		// the '=' is the padding character of the base32 encoder.
+		if cs[i] < 33 || cs[i] > 126 || cs[i] == '=' {
+
+			err = fmt.Errorf(
+				"charset '%s' contains invalid character %q at %d",
+				cs, cs[i], i,
+			)
+			return
+		}
+
+		if j := strings.IndexByte(cs[i+1:], cs[i]); j >= 0 {
+
+			err = fmt.Errorf(
+				"charset '%s' contains '%c' at both %d and %d",
+				cs, cs[i], i, i+1+j,
+			)
+			return
+		}
+	}
+
+	// The HRP can be empty, but otherwise it must be printable, and because
+	// there is no separator between the HRP and the data, none of its
+	// characters can be in the charset, or it would be impossible to tell
+	// where the HRP ends and the data begins by looking at the string.
+	for i := 0; i < len(hrp); i++ {
+
+		if hrp[i] < 33 || hrp[i] > 126 {
+
+			err = fmt.Errorf(
+				"HRP '%s' contains non printable character %q at %d",
+				hrp, hrp[i], i,
+			)
+			return
+		}
+
+		if strings.IndexByte(cs, hrp[i]) >= 0 {
+
+			err = fmt.Errorf(
+				"HRP '%s' contains '%c' at %d which is in the charset '%s'",
+				hrp, hrp[i], i, cs,
+			)
+			return
+		}
+	}
+
+	return
+}
+
+// applyOptions applies the options to the default settings, and checks that
+// they work together.
+func applyOptions(opts []Option) (o options, err error) {
+
+	o = defaultOptions()
+	for _, opt := range opts {
+
+		if err = opt(&o); err != nil {
+			return
+		}
+	}
+
+	// Without a version given, the first version that can encode the minimum
+	// check length is used, so that WithMinCheckBits works on its own.
+	for !o.versionSet && o.version < LatestVersion &&
+		o.minCheckLen > maxMinCheckLen(o.version) {
+
+		o.version++
+	}
+
+	err = validateOptions(o)
+
+	return
+}
+
+// buildOptions applies the options to the default settings, and checks that
+// the result works with the charset.
+func buildOptions(cs string, opts []Option) (o options, err error) {
+
+	if o, err = applyOptions(opts); err != nil {
+		return
+	}
+
+	if o.uppercase && caseFolder(cs) == nil {
+
+		err = fmt.Errorf(
+			"upper case output is not possible with mixed case charset '%s'",
+			cs,
+		)
+		return
+	}
+
+	// The group separator can only be checked against the charset once both
+	// are known.
+	if strings.ContainsAny(o.groupSeparator, cs) {
+
+		err = fmt.Errorf(
+			"group separator '%s' contains characters of the charset '%s'",
+			o.groupSeparator, cs,
+		)
+		return
+	}
+
+	return
+}
+
+// NewCodec creates a based32 codec with the given name, charset and Human
+// Readable Part, with the default settings of the package Codec changed by the
+// given options.
+//
+// An error is returned if the charset is not 32 unique printable characters,
+// or if the HRP has characters that are not printable or that are in the
+// charset. The chosen check algorithm is recorded in the CheckName field of the
+// returned codec, and is used by its Encoder, Check and Decoder alike.
+func NewCodec(name, cs, hrp string, opts ...Option) (
+	cdc *codec.Codec, err error,
+) {
+
+	if err = validate(name, cs, hrp); err != nil {
+		return
+	}
+
+	var o options
+	if o, err = buildOptions(cs, opts); err != nil {
+		return
+	}
+
+	cdc = makeCodec(name, cs, hrp, o)
+
+	return
+}
@@ -0,0 +1,161 @@
+package based32
+
+import (
+	"bytes"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"testing"
+)
+
+func TestNewCodec(t *testing.T) {
+
+	invalid := []struct {
+		name, cs, hrp string
+	}{
+		{"", charset, "QNTRL"},
+		{"short", charset[1:], "QNTRL"},
+		{"long", charset + "b", "QNTRL"},
+		{"duplicate", "q" + charset[1:31] + "q", "QNTRL"},
+		{"padding", "=" + charset[1:], "QNTRL"},
+		{"space", " " + charset[1:], "QNTRL"},
+		{"unprintable", charset, "QN\tTRL"},
+		{"overlap", charset, "QNTRLq"},
+	}
+
+	for _, v := range invalid {
+
+		if _, err := NewCodec(v.name, v.cs, v.hrp); err == nil {
+			t.Fatalf("'%s' codec was created", v.name)
+		}
+	}
+
+	// A codec with its own charset and HRP must round trip, and must produce
+	// different output to the package Codec.
+	const reversed = "l7aum6echk45nj3s0wdvt2fg8x9yrzpq"
+	cdc, err := NewCodec("Reversed", reversed, "REV-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := []byte("a custom variant of based32")
+	encoded, err := cdc.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded []byte
+	if decoded, err = cdc.Decode(encoded); err != nil {
+		t.Fatalf("'%s': %v", encoded, err)
+	}
+	if !bytes.Equal(decoded, input) {
+		t.Fatalf("got %x expected %x", decoded, input)
+	}
+
+	var standard string
+	if standard, err = Codec.Encode(input); err != nil {
+		t.Fatal(err)
+	}
+	if standard[len(Codec.HRP):] == encoded[len(cdc.HRP):] {
+		t.Fatal("custom charset produced the same data as the default")
+	}
+}
+
+func TestMinCheckBits(t *testing.T) {
+
+	strict, err := NewCodec("Strict", charset, "QNTRL",
+		WithVersion(Version1), WithMinCheckBits(32),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := NewCodec("Plain", charset, "QNTRL", WithVersion(Version1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rejected int
+	for l := 1; l <= 64; l++ {
+
+		input := make([]byte, l)
+		for i := range input {
+
+			input[i] = byte(i * l)
+		}
+
+		// Every length gets at least 4 check bytes, and the encoding still
+		// needs no padding, which the decoder would reject.
+		encoded, err := strict.Encode(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := strict.Decode(encoded)
+		if err != nil {
+			t.Fatalf("decoding '%s': %v", encoded, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Fatalf("got %x expected %x", decoded, input)
+		}
+		if checkLen := len(encoded[len("QNTRL"):])*5/8 - l - 1; checkLen < 4 {
+			t.Fatalf("%d bytes got a check of %d bytes", l, checkLen)
+		}
+
+		// Codes with a shorter check from a codec without the minimum are
+		// rejected, and the rest decode the same.
+		weak, err := plain.Encode(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = strict.Decode(weak)
+		switch {
+		case getCheckLen(l) < 4 && !errors.Is(err, proto.Error_CHECK_TOO_SHORT):
+			t.Fatalf("%d byte check gave error %v", getCheckLen(l), err)
+		case getCheckLen(l) >= 4 && err != nil:
+			t.Fatal(err)
+		case err != nil:
+			rejected++
+		}
+	}
+	if rejected == 0 {
+		t.Fatal("no codes were rejected")
+	}
+
+	for _, opts := range [][]Option{
+		{WithMinCheckBits(0)},
+		{WithMinCheckBits(33)},
+		{WithMinCheckBits(32), WithVersion(Version0)},
+		{WithVersion(Version0), WithMinCheckBits(32)},
+	} {
+
+		if _, err = NewCodec("Invalid", charset, "QNTRL", opts...); err == nil {
+			t.Fatalf("options %d gave no error", len(opts))
+		}
+		if _, err = NewMnemonicCodec("Invalid", English, opts...); err == nil {
+			t.Fatalf("options %d gave no error", len(opts))
+		}
+	}
+
+	if _, err = NewCodec("Version0", charset, "QNTRL",
+		WithMinCheckBits(24),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a version, the minimum picks the version that can encode it.
+	for bits, version := range map[int]int{24: Version0, 32: Version1} {
+
+		o, err := applyOptions([]Option{WithMinCheckBits(bits)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if o.version != version {
+			t.Fatalf("%d bits gave version %d expected %d",
+				bits, o.version, version,
+			)
+		}
+	}
+	if _, err = NewMnemonicCodec("Words", English,
+		WithMinCheckBits(32),
+	); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,315 @@
+package based32
+
+import (
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"sort"
+)
+
+// The Reed–Solomon code used by Corrector works in GF(32), the finite field
+// with 32 elements, so that each symbol of the code is one character of a
+// based32 string. The elements are the polynomials over GF(2) of degree less
+// than 5, which are added with XOR and multiplied modulo the primitive
+// polynomial x^5 + x^2 + 1. Every element other than zero is a power of
+// alpha, which is x, so multiplication is done with tables of logarithms.
+//
+// A code over GF(32) can have at most 31 symbols, which is why Corrector
+// splits longer strings into several codes.
+const (
+
+	// gfPoly is the primitive polynomial x^5 + x^2 + 1.
+	gfPoly = 0x25
+
+	// gfSize is the number of non zero elements of the field, which is also
+	// the longest a code can be.
+	gfSize = 31
+)
+
+// gfExp and gfLog are the powers and logarithms of alpha. The powers are
+// repeated so that the sum of two logarithms can be looked up without taking
+// the modulus.
+var gfExp, gfLog = func() (exp [2 * gfSize]byte, log [gfSize + 1]byte) {
+
+	x := 1
+	for i := 0; i < gfSize; i++ {
+
+		exp[i], exp[i+gfSize] = byte(x), byte(x)
+		log[x] = byte(i)
+
+		x <<= 1
+		if x&0x20 != 0 {
+
+			x ^= gfPoly
+		}
+	}
+
+	return
+}()
+
+// gfMul multiplies two elements of the field.
+func gfMul(a, b byte) byte {
+
+	if a == 0 || b == 0 {
+
+		return 0
+	}
+
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// gfDiv divides a by b, which must not be zero.
+func gfDiv(a, b byte) byte {
+
+	if a == 0 {
+
+		return 0
+	}
+
+	return gfExp[int(gfLog[a])+gfSize-int(gfLog[b])]
+}
+
+// gfPow returns alpha to the power of n, which may be negative.
+func gfPow(n int) byte {
+
+	n %= gfSize
+	if n < 0 {
+
+		n += gfSize
+	}
+
+	return gfExp[n]
+}
+
+// rsGenerator returns the generator polynomial of a code with the given number
+// of parity symbols, which has the powers of alpha from 1 to parity as its
+// roots. Its coefficients are listed from the highest power down, the first
+// being 1.
+func rsGenerator(parity int) (gen []byte) {
+
+	gen = []byte{1}
+	for i := 1; i <= parity; i++ {
+
+		// Multiply by (x - alpha^i), which is the same as (x + alpha^i).
+		root := gfPow(i)
+		next := make([]byte, len(gen)+1)
+		for j, g := range gen {
+
+			next[j] ^= g
+			next[j+1] ^= gfMul(g, root)
+		}
+		gen = next
+	}
+
+	return
+}
+
+// rsParity returns the parity symbols for the data, which are the remainder
+// of dividing the data, as a polynomial with its first symbol as the highest
+// power, shifted up by the number of parity symbols, by the generator.
+func rsParity(gen, data []byte) (parity []byte) {
+
+	parity = make([]byte, len(gen)-1)
+	for _, d := range data {
+
+		factor := d ^ parity[0]
+		copy(parity, parity[1:])
+		parity[len(parity)-1] = 0
+
+		for j := range parity {
+
+			parity[j] ^= gfMul(gen[j+1], factor)
+		}
+	}
+
+	return
+}
+
+// rsSyndromes returns the values of the codeword, as a polynomial, at the roots
+// of the generator, which are all zero if there are no errors.
+func rsSyndromes(codeword []byte, parity int) (syndromes []byte, bad bool) {
+
+	syndromes = make([]byte, parity)
+	for i := range syndromes {
+
+		root := gfPow(i + 1)
+		var s byte
+		for _, c := range codeword {
+
+			s = gfMul(s, root) ^ c
+		}
+
+		syndromes[i] = s
+		bad = bad || s != 0
+	}
+
+	return
+}
+
+// rsCorrect corrects the errors in the codeword, which is data followed by
+// the given number of parity symbols, in place. The erasures are the indexes
+// of symbols that are known to be wrong, such as characters that are not in
+// the charset, which can have any value in the codeword. Each erasure uses up
+// one parity symbol and each error at an unknown place two, so with no
+// erasures up to half as many errors as there are parity symbols can be
+// corrected. The indexes of the symbols that were changed, and of all of the
+// erasures, are returned in order. If there are more errors than that, which
+// can't always be told apart from fewer, CHECK_FAILED is returned and the
+// codeword is left as it was.
+func rsCorrect(codeword []byte, parity int, erasures []int) (
+	fixed []int, err error,
+) {
+
+	if len(erasures) > parity {
+
+		return nil, proto.Error_CHECK_FAILED
+	}
+
+	syndromes, bad := rsSyndromes(codeword, parity)
+	if !bad {
+
+		// The erased symbols happen to have the right values.
+		fixed = append(fixed, erasures...)
+		sort.Ints(fixed)
+		return
+	}
+
+	// The erasure locator has the inverses of the powers of alpha for the
+	// positions of the erasures as its roots, listed from the lowest power up.
+	erasureLocator := []byte{1}
+	for _, i := range erasures {
+
+		power := gfPow(len(codeword) - 1 - i)
+		next := make([]byte, len(erasureLocator)+1)
+		for j, l := range erasureLocator {
+
+			next[j] ^= l
+			next[j+1] ^= gfMul(l, power)
+		}
+		erasureLocator = next
+	}
+
+	// The Berlekamp-Massey algorithm finds the shortest linear recurrence
+	// that generates the syndromes, whose coefficients are those of the error
+	// locator polynomial, listed from the lowest power up. Its roots are the
+	// inverses of the powers of alpha for the positions of the errors.
+	// Starting from the erasure locator, with the erasures counted as errors
+	// already found, makes it find the locator of both together.
+	locator, prev := erasureLocator, erasureLocator
+	errorCount, shift, lastDiscrepancy := len(erasures), 1, byte(1)
+	for n := len(erasures); n < parity; n++ {
+
+		discrepancy := syndromes[n]
+		for i := 1; i <= errorCount && i <= n && i < len(locator); i++ {
+
+			discrepancy ^= gfMul(locator[i], syndromes[n-i])
+		}
+
+		if discrepancy == 0 {
+
+			shift++
+			continue
+		}
+
+		factor := gfDiv(discrepancy, lastDiscrepancy)
+		next := make([]byte, len(locator))
+		copy(next, locator)
+		if need := len(prev) + shift; len(next) < need {
+
+			next = append(next, make([]byte, need-len(next))...)
+		}
+		for i, p := range prev {
+
+			next[i+shift] ^= gfMul(factor, p)
+		}
+
+		if 2*errorCount <= n+len(erasures) {
+
+			errorCount = n + 1 + len(erasures) - errorCount
+			prev, lastDiscrepancy, shift = locator, discrepancy, 1
+		} else {
+
+			shift++
+		}
+		locator = next
+	}
+
+	// The errors that are not erasures each need two parity symbols.
+	if 2*errorCount-len(erasures) > parity {
+
+		return nil, proto.Error_CHECK_FAILED
+	}
+
+	// The error evaluator polynomial is the product of the syndromes, as a
+	// polynomial from the lowest power up, and the locator, cut off at the
+	// number of syndromes.
+	evaluator := make([]byte, parity)
+	for i, s := range syndromes {
+
+		for j := 0; j < len(locator) && i+j < parity; j++ {
+
+			evaluator[i+j] ^= gfMul(s, locator[j])
+		}
+	}
+
+	// Each position of the codeword is tried as a root of the locator, which
+	// is known as a Chien search. The symbol at index i is the coefficient of
+	// the power len(codeword)-1-i, and the value of the error there is found
+	// with Forney's formula.
+	corrections := make([]byte, len(codeword))
+	for i := range codeword {
+
+		inverse := gfPow(-(len(codeword) - 1 - i))
+
+		var value, derivative, evaluated byte
+		for j := len(locator) - 1; j >= 0; j-- {
+
+			value = gfMul(value, inverse) ^ locator[j]
+		}
+		if value != 0 {
+			continue
+		}
+
+		// The formal derivative in a field of characteristic 2 keeps only
+		// the odd powers.
+		for j := len(locator) - 1; j >= 1; j-- {
+
+			if j%2 == 1 {
+
+				derivative ^= gfMul(locator[j], gfPow(int(gfLog[inverse])*(j-1)))
+			}
+		}
+		for j := len(evaluator) - 1; j >= 0; j-- {
+
+			evaluated = gfMul(evaluated, inverse) ^ evaluator[j]
+		}
+
+		if derivative == 0 {
+
+			return nil, proto.Error_CHECK_FAILED
+		}
+
+		corrections[i] = gfDiv(evaluated, derivative)
+		fixed = append(fixed, i)
+	}
+
+	// If the locator has roots that are not positions of the codeword, there
+	// were more errors than can be corrected.
+	if len(fixed) != errorCount {
+
+		return nil, proto.Error_CHECK_FAILED
+	}
+
+	corrected := make([]byte, len(codeword))
+	for i := range codeword {
+
+		corrected[i] = codeword[i] ^ corrections[i]
+	}
+
+	if _, bad = rsSyndromes(corrected, parity); bad {
+
+		return nil, proto.Error_CHECK_FAILED
+	}
+
+	copy(codeword, corrected)
+
+	return
+}
@@ -0,0 +1,189 @@
+package based32
+
+import (
+	"bytes"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestGaloisField(t *testing.T) {
+
+	// The powers of alpha are every element other than zero, once each.
+	seen := make(map[byte]bool)
+	for i := 0; i < gfSize; i++ {
+
+		x := gfPow(i)
+		if x == 0 || x >= 32 || seen[x] {
+			t.Fatalf("alpha^%d is %d", i, x)
+		}
+		seen[x] = true
+		if int(gfLog[x]) != i {
+			t.Fatalf("log of alpha^%d is %d", i, gfLog[x])
+		}
+	}
+	if gfPow(gfSize) != 1 || gfPow(-1) != gfPow(gfSize-1) {
+		t.Fatal("powers of alpha don't wrap around")
+	}
+
+	// Multiplication is the same as multiplying the polynomials and taking
+	// the remainder by the primitive polynomial, and division undoes it.
+	for a := 0; a < 32; a++ {
+
+		for b := 0; b < 32; b++ {
+
+			var product int
+			for i := 0; i < 5; i++ {
+
+				if b&(1<<i) != 0 {
+
+					product ^= a << i
+				}
+			}
+			for i := 9; i >= 5; i-- {
+
+				if product&(1<<i) != 0 {
+
+					product ^= gfPoly << (i - 5)
+				}
+			}
+
+			if got := gfMul(byte(a), byte(b)); got != byte(product) {
+				t.Fatalf("%d * %d gave %d expected %d", a, b, got, product)
+			}
+			if b != 0 && gfDiv(byte(product), byte(b)) != byte(a) {
+				t.Fatalf("%d / %d is not %d", product, b, a)
+			}
+		}
+	}
+}
+
+func TestReedSolomon(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+	for parity := 2; parity <= 2*MaxCorrectable; parity += 2 {
+
+		gen := rsGenerator(parity)
+		for dataLen := 1; dataLen+parity <= gfSize; dataLen++ {
+
+			data := make([]byte, dataLen)
+			for i := range data {
+
+				data[i] = byte(rng.Intn(32))
+			}
+			codeword := append(data, rsParity(gen, data)...)
+			if _, bad := rsSyndromes(codeword, parity); bad {
+				t.Fatalf("parity %d: codeword %v has syndromes", parity, codeword)
+			}
+
+			// Any mix of errors and erasures that uses up to the parity is
+			// corrected, with two parity symbols for each error and one for
+			// each erasure.
+			for errs := 0; errs <= parity/2; errs++ {
+
+				for erased := 0; 2*errs+erased <= parity &&
+					errs+erased <= len(codeword); erased++ {
+
+					if errs+erased == 0 {
+						continue
+					}
+
+					damaged := append([]byte{}, codeword...)
+					positions := rng.Perm(len(codeword))[:errs+erased]
+					erasures := append([]int{}, positions[errs:]...)
+					sort.Ints(erasures)
+					for _, p := range positions[:errs] {
+
+						damaged[p] ^= byte(rng.Intn(31) + 1)
+					}
+					for _, p := range erasures {
+
+						damaged[p] = byte(rng.Intn(32))
+					}
+
+					fixed, err := rsCorrect(damaged, parity, erasures)
+					if err != nil {
+						t.Fatalf("parity %d length %d errors %d erasures "+
+							"%d: %v", parity, len(codeword), errs, erased, err,
+						)
+					}
+					if !bytes.Equal(damaged, codeword) {
+						t.Fatalf("corrected to %v expected %v", damaged,
+							codeword,
+						)
+					}
+
+					// All of the erasures are reported, even those that
+					// happened to have the right value.
+					sort.Ints(positions)
+					if !reflect.DeepEqual(fixed, positions) {
+						t.Fatalf("fixed %v expected %v", fixed, positions)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestReedSolomonLimits(t *testing.T) {
+
+	const parity = 4
+	gen := rsGenerator(parity)
+	data := []byte("kitchen sink")
+	for i := range data {
+
+		data[i] &= 31
+	}
+	codeword := append(data, rsParity(gen, data)...)
+
+	// More erasures than parity symbols can't be corrected at all.
+	damaged := append([]byte{}, codeword...)
+	if _, err := rsCorrect(damaged, parity, []int{0, 1, 2, 3, 4}); !errors.Is(
+		err, proto.Error_CHECK_FAILED,
+	) {
+		t.Fatalf("got error %v", err)
+	}
+
+	// Three errors are more than 4 parity symbols can correct. They are
+	// either found to be too many, or corrected into a different codeword,
+	// and the codeword is only changed if it was corrected.
+	rng := rand.New(rand.NewSource(4))
+	var detected int
+	for i := 0; i < 100; i++ {
+
+		damaged = append([]byte{}, codeword...)
+		for _, p := range rng.Perm(len(codeword))[:3] {
+
+			damaged[p] ^= byte(rng.Intn(31) + 1)
+		}
+		before := append([]byte{}, damaged...)
+
+		fixed, err := rsCorrect(damaged, parity, nil)
+		switch {
+		case err != nil:
+			detected++
+			if !bytes.Equal(damaged, before) {
+				t.Fatal("codeword was changed when correction failed")
+			}
+		case bytes.Equal(damaged, codeword):
+			t.Fatalf("3 errors were corrected, fixing %v", fixed)
+		default:
+			if _, bad := rsSyndromes(damaged, parity); bad {
+				t.Fatalf("corrected to %v which is not a codeword", damaged)
+			}
+		}
+	}
+	if detected == 0 {
+		t.Fatal("too many errors were never detected")
+	}
+
+	// An erasure with the right value needs no correcting but is reported.
+	damaged = append([]byte{}, codeword...)
+	fixed, err := rsCorrect(damaged, parity, []int{3})
+	if err != nil || !reflect.DeepEqual(fixed, []int{3}) {
+		t.Fatalf("fixed %v error %v", fixed, err)
+	}
+}
@@ -0,0 +1,370 @@
+package based32
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"io"
+	"lukechampine.com/blake3"
+)
+
+// The streaming format splits the data into chunks of at most ChunkSize bytes,
+// each of which is encoded as a separate based32 string on its own line, so
+// each chunk is verified by its own check as soon as it is read, and neither
+// the encoder nor the decoder ever holds more than one chunk in memory.
+//
+// Before encoding, each chunk is prefixed with a 4 byte big endian sequence
+// number and a flags byte. The last chunk is marked with a flag and carries the
+// Blake3 hash of all of the data of the stream after its own data, so chunks
+// that are missing, reordered, or spliced in from another stream are detected.
+// The last chunk is always present, even if it has no data of its own.
+const (
+
+	// ChunkSize is the largest amount of data in one chunk of a stream.
+	ChunkSize = 1024
+
+	// chunkHeaderLen is the length of the sequence number and flags.
+	chunkHeaderLen = 5
+
+	// flagLast marks the last chunk of a stream.
+	flagLast = 1
+
+	// streamHashLen is the length of the hash of the stream data.
+	streamHashLen = 32
+
+	// maxChunkLen is the length of the largest chunk before encoding.
+	maxChunkLen = chunkHeaderLen + ChunkSize + streamHashLen
+)
+
+var (
+
+	// ErrChunkSequence is returned by a Decoder when a chunk has a different
+	// sequence number than the one expected, which means chunks are missing
+	// or out of order.
+	ErrChunkSequence = errors.New("stream chunk out of sequence")
+
+	// ErrStreamHash is returned by a Decoder when the hash in the last chunk
+	// does not match the data of the stream.
+	ErrStreamHash = errors.New("stream hash does not match data")
+
+	// ErrTrailingData is returned by a Decoder when there is more input after
+	// the last chunk.
+	ErrTrailingData = errors.New("data found after end of stream")
+
+	// ErrClosed is returned by an Encoder that is written to after it has been
+	// closed.
+	ErrClosed = errors.New("write to closed stream encoder")
+)
+
+// maxLineLen returns the length of the longest line that the Decoder accepts
+// for the codec, which bounds the memory it uses when given garbage without
+// line breaks.
+//
+// This is found by encoding a chunk of the largest size, which counts the Human
+// Readable Part, the check, and any group separators that the codec adds. The
+// chunk is filled with data that doesn't compress, so that codecs which
+// compress encode it at its full length. Lines are allowed to be twice as long,
+// as they may have been written out with more separators, and the words of
+// word lists are not all the same length, so other data can encode longer.
+func maxLineLen(cdc *codec.Codec) int {
+
+	chunk := make([]byte, maxChunkLen)
+	_, _ = blake3.New(streamHashLen, nil).XOF().Read(chunk)
+
+	encoded, err := cdc.Encode(chunk)
+	if err != nil {
+
+		// No chunk can be encoded with this codec, so none can be decoded
+		// either, and the decoder fails on the first line anyway.
+		return bufio.MaxScanTokenSize
+	}
+
+	return 2 * len(encoded)
+}
+
+// Encoder is an io.WriteCloser that writes the data written to it to an
+// underlying io.Writer in the based32 streaming format. Close must be called
+// to write the final chunk.
+type Encoder struct {
+	w      io.Writer
+	cdc    *codec.Codec
+	buf    []byte
+	seq    uint32
+	hash   *blake3.Hasher
+	closed bool
+	err    error
+}
+
+// NewEncoder creates an Encoder that writes the stream to w, using the package
+// Codec to encode the chunks.
+func NewEncoder(w io.Writer) (e *Encoder) {
+
+	return NewCodecEncoder(Codec, w)
+}
+
+// NewCodecEncoder creates an Encoder that writes the stream to w using the
+// given codec to encode the chunks.
+func NewCodecEncoder(cdc *codec.Codec, w io.Writer) (e *Encoder) {
+
+	e = &Encoder{
+		w:    w,
+		cdc:  cdc,
+		buf:  make([]byte, chunkHeaderLen, maxChunkLen),
+		hash: blake3.New(streamHashLen, nil),
+	}
+
+	return
+}
+
+// Write buffers the data and writes out a chunk each time ChunkSize bytes have
+// been collected.
+func (e *Encoder) Write(p []byte) (n int, err error) {
+
+	if e.closed {
+
+		err = ErrClosed
+		return
+	}
+
+	if e.err != nil {
+
+		err = e.err
+		return
+	}
+
+	for len(p) > 0 {
+
+		// Fill up the chunk with as much as will fit.
+		take := ChunkSize - (len(e.buf) - chunkHeaderLen)
+		if take > len(p) {
+
+			take = len(p)
+		}
+
+		e.buf = append(e.buf, p[:take]...)
+		_, _ = e.hash.Write(p[:take])
+		p = p[take:]
+		n += take
+
+		// Only write full chunks here, as the last chunk, which may be full
+		// or not, must be written by Close.
+		if len(e.buf)-chunkHeaderLen == ChunkSize && len(p) > 0 {
+
+			if err = e.flush(false); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// flush encodes the current chunk and writes it out as a line.
+func (e *Encoder) flush(last bool) (err error) {
+
+	binary.BigEndian.PutUint32(e.buf, e.seq)
+	e.buf[4] = 0
+	if last {
+
+		e.buf[4] = flagLast
+		e.buf = e.hash.Sum(e.buf)
+	}
+
+	var line string
+	if line, err = e.cdc.Encode(e.buf); err != nil {
+
+		e.err = err
+		return
+	}
+
+	if _, err = io.WriteString(e.w, line+"\n"); err != nil {
+
+		e.err = err
+		return
+	}
+
+	e.seq++
+	e.buf = e.buf[:chunkHeaderLen]
+
+	return
+}
+
+// Close writes the last chunk. It does not close the underlying io.Writer.
+func (e *Encoder) Close() (err error) {
+
+	if e.closed {
+		return
+	}
+
+	if e.err != nil {
+
+		err = e.err
+		return
+	}
+
+	e.closed = true
+
+	return e.flush(true)
+}
+
+// Decoder is an io.Reader that reads a stream in the based32 streaming format
+// from an underlying io.Reader and returns the decoded data.
+//
+// Data is returned as soon as the chunk containing it has been verified by its
+// check, so a consumer that needs to be sure of the stream as a whole must read
+// until io.EOF, as errors with the stream as a whole can only be detected at
+// the end.
+type Decoder struct {
+	scanner *bufio.Scanner
+	cdc     *codec.Codec
+	buf     []byte
+	seq     uint32
+	hash    *blake3.Hasher
+	done    bool
+	err     error
+}
+
+// NewDecoder creates a Decoder that reads the stream from r, using the package
+// Codec to decode the chunks.
+func NewDecoder(r io.Reader) (d *Decoder) {
+
+	return NewCodecDecoder(Codec, r)
+}
+
+// NewCodecDecoder creates a Decoder that reads the stream from r using the
+// given codec to decode the chunks.
+func NewCodecDecoder(cdc *codec.Codec, r io.Reader) (d *Decoder) {
+
+	d = &Decoder{
+		scanner: bufio.NewScanner(r),
+		cdc:     cdc,
+		hash:    blake3.New(streamHashLen, nil),
+	}
+
+	// The scanner only holds one line at a time, and refuses lines longer than
+	// a chunk can be, so memory use is bounded whatever the input is.
+	d.scanner.Buffer(nil, maxLineLen(cdc))
+
+	return
+}
+
+// next reads a line, skipping blank ones, returning false at the end of the
+// input.
+func (d *Decoder) next() (line string, ok bool, err error) {
+
+	for d.scanner.Scan() {
+
+		if line = d.scanner.Text(); line != "" && line != "\r" {
+
+			ok = true
+			return
+		}
+	}
+
+	err = d.scanner.Err()
+
+	return
+}
+
+// Read returns decoded data from the stream, reading and verifying the next
+// chunk whenever the previous one has been used up.
+func (d *Decoder) Read(p []byte) (n int, err error) {
+
+	for len(d.buf) < 1 {
+
+		switch {
+		case d.err != nil:
+
+			err = d.err
+			return
+
+		case d.done:
+
+			err = io.EOF
+			return
+		}
+
+		d.err = d.readChunk()
+	}
+
+	n = copy(p, d.buf)
+	d.buf = d.buf[n:]
+
+	return
+}
+
+// readChunk reads, decodes and verifies the next chunk of the stream.
+func (d *Decoder) readChunk() (err error) {
+
+	line, ok, err := d.next()
+	if err != nil {
+		return
+	}
+
+	// Running out of input before the last chunk means the stream was cut
+	// short.
+	if !ok {
+
+		err = io.ErrUnexpectedEOF
+		return
+	}
+
+	var chunk []byte
+	if chunk, err = d.cdc.Decode(line); err != nil {
+		return
+	}
+
+	if len(chunk) < chunkHeaderLen {
+
+		err = io.ErrUnexpectedEOF
+		return
+	}
+
+	if binary.BigEndian.Uint32(chunk) != d.seq {
+
+		err = ErrChunkSequence
+		return
+	}
+	d.seq++
+
+	last := chunk[4]&flagLast != 0
+	data := chunk[chunkHeaderLen:]
+	if !last {
+
+		_, _ = d.hash.Write(data)
+		d.buf = data
+		return
+	}
+
+	// The last chunk ends with the hash of all of the data.
+	if len(data) < streamHashLen {
+
+		err = io.ErrUnexpectedEOF
+		return
+	}
+
+	sum := data[len(data)-streamHashLen:]
+	data = data[:len(data)-streamHashLen]
+	_, _ = d.hash.Write(data)
+
+	if string(d.hash.Sum(nil)) != string(sum) {
+
+		err = ErrStreamHash
+		return
+	}
+
+	if _, ok, err = d.next(); err != nil {
+		return
+	}
+	if ok {
+
+		err = ErrTrailingData
+		return
+	}
+
+	d.buf = data
+	d.done = true
+
+	return
+}
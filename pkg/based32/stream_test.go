@@ -0,0 +1,138 @@
+package based32
+
+import (
+	"bytes"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"io"
+	"lukechampine.com/blake3"
+	"strings"
+	"testing"
+)
+
+// encodeStream encodes data with the streaming encoder, writing it in pieces
+// of the given size to exercise the buffering.
+func encodeStream(t *testing.T, data []byte, piece int) string {
+
+	var out strings.Builder
+	enc := NewEncoder(&out)
+	for i := 0; i < len(data); i += piece {
+
+		end := i + piece
+		if end > len(data) {
+
+			end = len(data)
+		}
+
+		if _, err := enc.Write(data[i:end]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return out.String()
+}
+
+func TestStream(t *testing.T) {
+
+	// Make a few megabytes of data from a Blake3 extendable output.
+	data := make([]byte, 3<<20+123)
+	_, _ = blake3.New(32, nil).XOF().Read(data)
+
+	for _, size := range []int{0, 1, ChunkSize, ChunkSize + 1, len(data)} {
+
+		encoded := encodeStream(t, data[:size], 777)
+
+		// There is always at least one chunk, and a full last chunk does not
+		// need another one after it.
+		expected := (size + ChunkSize - 1) / ChunkSize
+		if expected < 1 {
+
+			expected = 1
+		}
+		lines := strings.Split(strings.TrimSpace(encoded), "\n")
+		if len(lines) != expected {
+			t.Fatalf("%d bytes made %d chunks", size, len(lines))
+		}
+
+		decoded, err := io.ReadAll(NewDecoder(strings.NewReader(encoded)))
+		if err != nil {
+			t.Fatalf("%d bytes: %v", size, err)
+		}
+		if !bytes.Equal(decoded, data[:size]) {
+			t.Fatalf("%d bytes did not round trip", size)
+		}
+	}
+
+	encoded := encodeStream(t, data[:ChunkSize*4], 4096)
+	lines := strings.Split(strings.TrimSpace(encoded), "\n")
+
+	broken := map[string][]string{
+		"truncated": lines[:3],
+		"missing":   append(append([]string{}, lines[:1]...), lines[2:]...),
+		"reordered": {lines[1], lines[0], lines[2], lines[3]},
+		"trailing":  append(append([]string{}, lines...), lines[0]),
+	}
+
+	// Splice in a chunk from another stream with the same sequence number.
+	other := strings.Split(encodeStream(t, data[1:ChunkSize*4+1], 4096), "\n")
+	broken["spliced"] = []string{lines[0], other[1], lines[2], lines[3]}
+
+	for name, l := range broken {
+
+		_, err := io.ReadAll(
+			NewDecoder(strings.NewReader(strings.Join(l, "\n"))),
+		)
+		if err == nil {
+			t.Fatalf("%s stream was decoded", name)
+		}
+	}
+
+	// Writing after Close must fail.
+	enc := NewEncoder(io.Discard)
+	_ = enc.Close()
+	if _, err := enc.Write([]byte{1}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("write after close gave %v", err)
+	}
+}
+
+func TestStreamCodecs(t *testing.T) {
+
+	// A group separator of two characters after every character makes the
+	// lines three times as long as the data part, and words are longer still.
+	grouped, err := NewCodec("Grouped", charset, "QNTRL", WithGrouping(1, ", "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	words, err := NewMnemonicCodec("Words", English)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := make([]byte, 3*ChunkSize)
+	_, _ = blake3.New(32, nil).XOF().Read(data)
+	for _, cdc := range []*codec.Codec{grouped, words} {
+
+		var out strings.Builder
+		enc := NewCodecEncoder(cdc, &out)
+		if _, err = enc.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err = enc.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, err := io.ReadAll(
+			NewCodecDecoder(cdc, strings.NewReader(out.String())),
+		)
+		if err != nil {
+			t.Fatalf("%s: %v", cdc.Name, err)
+		}
+		if !bytes.Equal(decoded, data) {
+			t.Fatalf("%s stream did not round trip", cdc.Name)
+		}
+	}
+}
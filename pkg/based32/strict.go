@@ -0,0 +1,42 @@
+package based32
+
+import (
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/proto"
+)
+
+// canonical returns an error if the input that decoded to the data is not
+// exactly what the codec encodes the data as, for the decoders in strict mode.
+// The check length of the input is compared first, as this gives a more
+// specific error, but only if the payload, the bytes between the header and
+// the check, is as long as the encoder makes it. The check length depends on
+// that length, so when the data was compressed differently the check length
+// would be wrong as well, and that is not the problem to report.
+func canonical(cdc *codec.Codec, o options, input, data []byte,
+	payloadLen, checkLen int,
+) (err error) {
+
+	payload, _ := o.compress(data)
+	if payloadLen == len(payload) && checkLen != o.checkLen(len(payload)) {
+
+		return decodeError(proto.Error_INVALID_CHECK_LENGTH, -1)
+	}
+
+	var encoded []byte
+	if encoded, err = cdc.AppendEncoder(nil, data); err != nil {
+
+		return wrapDecodeError(err)
+	}
+
+	// The offset of the error is the first place the input differs from the
+	// encoding, which may be the end of the shorter of the two.
+	for i := 0; i < len(input) || i < len(encoded); i++ {
+
+		if i >= len(input) || i >= len(encoded) || input[i] != encoded[i] {
+
+			return decodeError(proto.Error_NON_CANONICAL, i)
+		}
+	}
+
+	return
+}
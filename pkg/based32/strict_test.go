@@ -0,0 +1,130 @@
+package based32
+
+import (
+	"bytes"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/codecer/codecertest"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"strings"
+	"testing"
+)
+
+func TestStrict(t *testing.T) {
+
+	strict, err := NewCodec("Strict", charset, "QNTRL", WithStrict())
+	if err != nil {
+		t.Fatal(err)
+	}
+	codecertest.Run(t, strict)
+
+	// A codec that chooses a longer check than the usual one, which the
+	// check passes, but which is not the encoding of the data.
+	input := []byte("kitchen") // 7 bytes, which gets 2 bytes of check.
+	o := defaultOptions()
+	o.minCheckLen = getCheckLen(len(input)) + 1
+	long, err := makeCodec("Long", charset, "QNTRL", o).Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v1, err := NewCodec("Version1", charset, "QNTRL", WithVersion(Version1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	version1, err := v1.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := strict.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		input  string
+		kind   proto.Error
+		offset int
+	}{
+		{long, proto.Error_INVALID_CHECK_LENGTH, -1},
+		{version1, proto.Error_NON_CANONICAL, 5},
+		{"QNTRL" + strings.ToUpper(encoded[5:]), proto.Error_NON_CANONICAL, 5},
+		{"qntrl" + encoded[5:], proto.Error_NON_CANONICAL, 0},
+		{Group(Codec, encoded, 4, "-"), proto.Error_NON_CANONICAL, 5},
+		{encoded + "\n", proto.Error_NON_CANONICAL, len(encoded)},
+	} {
+
+		// The package Codec accepts all of these as the same data.
+		decoded, err := Codec.Decode(c.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Fatalf("'%s' gave %x expected %x", c.input, decoded, input)
+		}
+
+		_, err = strict.Decode(c.input)
+		var de *DecodeError
+		if !errors.As(err, &de) || de.Kind != c.kind || de.Offset != c.offset {
+			t.Fatalf("'%s' gave error %v expected %v at offset %d",
+				c.input, err, c.kind, c.offset,
+			)
+		}
+	}
+
+	// The options of the codec are part of what is canonical.
+	grouped, err := NewCodec("Grouped", charset, "QNTRL",
+		WithStrict(), WithGrouping(4, "-"), WithUppercase(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded, err = grouped.Encode(input); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = grouped.Decode(encoded); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = grouped.Decode(strings.ToLower(encoded)); !errors.Is(
+		err, proto.Error_NON_CANONICAL,
+	) {
+		t.Fatalf("lower case gave error %v", err)
+	}
+}
+
+func TestStrictMnemonic(t *testing.T) {
+
+	strict, err := NewMnemonicCodec("Strict", English, WithStrict())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := []byte("kitchensink")
+	words, err := strict.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = strict.Decode(words); err != nil {
+		t.Fatal(err)
+	}
+
+	// Words given by the start only, or in another case or with other
+	// separators, are the same data but not the same string.
+	fields := strings.Fields(words)
+	for _, variant := range []string{
+		strings.Replace(words, fields[0], fields[0][:4], 1),
+		strings.ToUpper(words),
+		strings.Join(fields, "-"),
+		words + " ",
+	} {
+
+		if _, err = Mnemonic.Decode(variant); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = strict.Decode(variant); !errors.Is(
+			err, proto.Error_NON_CANONICAL,
+		) {
+			t.Fatalf("'%s' gave error %v", variant, err)
+		}
+	}
+}
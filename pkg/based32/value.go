@@ -0,0 +1,149 @@
+package based32
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+)
+
+// Value holds bytes that are encoded with the package Codec wherever they are
+// written out as text, and that can only be read back from text that decodes
+// correctly.
+//
+// By implementing the standard interfaces for converting to and from text,
+// JSON and database columns, a Value can be used as a field of a struct in a
+// JSON API or a row scanned from a database, and invalid codes are rejected
+// at the boundary without any code to call the codec by hand:
+//
+//	type Account struct {
+//		ID   based32.Value `json:"id"`
+//		Name string        `json:"name"`
+//	}
+//
+// A nil Value is written as JSON null and SQL NULL, and an empty but not nil
+// Value can't be written at all, as the codec can't encode nothing.
+type Value []byte
+
+// These ensure the interfaces are satisfied, as for codec.Codec.
+var (
+	_ encoding.TextMarshaler   = Value{}
+	_ encoding.TextUnmarshaler = &Value{}
+	_ json.Marshaler           = Value{}
+	_ json.Unmarshaler         = &Value{}
+	_ sql.Scanner              = &Value{}
+	_ driver.Valuer            = Value{}
+	_ fmt.Stringer             = Value{}
+)
+
+// String returns the encoding of the value, or an empty string if it can't be
+// encoded.
+func (v Value) String() string {
+
+	text, err := v.MarshalText()
+	if err != nil {
+
+		return ""
+	}
+
+	return string(text)
+}
+
+// MarshalText implements encoding.TextMarshaler by encoding the value.
+func (v Value) MarshalText() (text []byte, err error) {
+
+	return Codec.AppendEncode(nil, v)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler by decoding the text. If
+// the text is not valid, the error from the codec is returned and the value is
+// not changed.
+func (v *Value) UnmarshalText(text []byte) (err error) {
+
+	var decoded []byte
+	if decoded, err = Codec.AppendDecode(nil, text); err != nil {
+		return
+	}
+
+	*v = decoded
+
+	return
+}
+
+// MarshalJSON implements json.Marshaler, writing the encoding of the value as
+// a JSON string, or null for a nil value.
+func (v Value) MarshalJSON() (output []byte, err error) {
+
+	if v == nil {
+
+		return []byte("null"), nil
+	}
+
+	var text []byte
+	if text, err = v.MarshalText(); err != nil {
+		return
+	}
+
+	// The HRP and the charset of the package Codec have no characters that
+	// need to be escaped in JSON, so the encoding only needs to be quoted.
+	output = make([]byte, 0, len(text)+2)
+	output = append(output, '"')
+	output = append(output, text...)
+	output = append(output, '"')
+
+	return
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading a JSON string with
+// UnmarshalText, and setting the value to nil for null.
+func (v *Value) UnmarshalJSON(input []byte) (err error) {
+
+	if bytes.Equal(input, []byte("null")) {
+
+		*v = nil
+		return
+	}
+
+	var text string
+	if err = json.Unmarshal(input, &text); err != nil {
+		return
+	}
+
+	return v.UnmarshalText([]byte(text))
+}
+
+// Scan implements sql.Scanner, reading the value from a text column, which
+// drivers give as a string or []byte, and setting it to nil for NULL.
+func (v *Value) Scan(src interface{}) (err error) {
+
+	switch s := src.(type) {
+	case nil:
+		*v = nil
+	case string:
+		err = v.UnmarshalText([]byte(s))
+	case []byte:
+		err = v.UnmarshalText(s)
+	default:
+		err = fmt.Errorf("can't scan %T into based32.Value", src)
+	}
+
+	return
+}
+
+// Value implements driver.Valuer, giving the encoding of the value to be
+// stored in a text column, or NULL for a nil value.
+func (v Value) Value() (value driver.Value, err error) {
+
+	if v == nil {
+		return
+	}
+
+	var text []byte
+	if text, err = v.MarshalText(); err != nil {
+		return
+	}
+
+	return string(text), nil
+}
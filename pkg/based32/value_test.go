@@ -0,0 +1,96 @@
+package based32
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"testing"
+)
+
+func TestValue(t *testing.T) {
+
+	type account struct {
+		ID     Value `json:"id"`
+		Parent Value `json:"parent"`
+	}
+
+	id := Value("kitchensink")
+	encoded, err := Codec.Encode(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id.String() != encoded {
+		t.Fatalf("got '%s' expected '%s'", id, encoded)
+	}
+
+	// JSON round trip, with nil as null.
+	out, err := json.Marshal(account{ID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"id":"` + encoded + `","parent":null}`
+	if string(out) != expected {
+		t.Fatalf("got %s expected %s", out, expected)
+	}
+
+	var a account
+	if err = json.Unmarshal(out, &a); err != nil {
+		t.Fatal(err)
+	}
+	if string(a.ID) != string(id) || a.Parent != nil {
+		t.Fatalf("got %x and %x", a.ID, a.Parent)
+	}
+
+	// Invalid codes are rejected with the error of the codec.
+	corrupted := encoded[:len(encoded)-1] + "q"
+	if corrupted == encoded {
+		corrupted = encoded[:len(encoded)-1] + "p"
+	}
+	err = json.Unmarshal([]byte(`{"id":"`+corrupted+`"}`), &a)
+	if !errors.Is(err, proto.Error_CHECK_FAILED) {
+		t.Fatalf("'%s' gave error %v", corrupted, err)
+	}
+
+	// Nothing can't be encoded.
+	if _, err = json.Marshal(account{ID: Value{}}); err == nil {
+		t.Fatal("empty value marshalled without error")
+	}
+
+	// Database round trip, with nil as NULL.
+	dv, err := id.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dv != encoded {
+		t.Fatalf("got %v expected '%s'", dv, encoded)
+	}
+
+	if dv, err = Value(nil).Value(); dv != nil || err != nil {
+		t.Fatalf("got %v and error %v for nil", dv, err)
+	}
+
+	for _, src := range []interface{}{encoded, []byte(encoded)} {
+
+		var v Value
+		if err = v.Scan(src); err != nil {
+			t.Fatal(err)
+		}
+		if string(v) != string(id) {
+			t.Fatalf("scanning %v gave %x", src, v)
+		}
+	}
+
+	v := id
+	if err = v.Scan(nil); err != nil || v != nil {
+		t.Fatalf("scanning NULL gave %x and error %v", v, err)
+	}
+
+	if err = v.Scan(corrupted); !errors.Is(err, proto.Error_CHECK_FAILED) {
+		t.Fatalf("scanning '%s' gave error %v", corrupted, err)
+	}
+
+	if err = v.Scan(42); err == nil {
+		t.Fatal("scanning an integer gave no error")
+	}
+}
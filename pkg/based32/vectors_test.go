@@ -0,0 +1,121 @@
+package based32
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"os"
+	"testing"
+)
+
+//go:generate go run ../../cmd/basedvec -o testdata/vectors.json
+
+// The vectors are read into types of their own here, rather than those of
+// cmd/basedvec, so that this test reads the file the same way an
+// implementation in another language would.
+type testVectors struct {
+	Codec   string `json:"codec"`
+	HRP     string `json:"hrp"`
+	Charset string `json:"charset"`
+	Valid   []struct {
+		Description string `json:"description"`
+		Version     int    `json:"version"`
+		Hex         string `json:"hex"`
+		Encoded     string `json:"encoded"`
+		DecodeOnly  bool   `json:"decodeOnly"`
+	} `json:"valid"`
+	Invalid []struct {
+		Description string `json:"description"`
+		Encoded     string `json:"encoded"`
+		Error       string `json:"error"`
+	} `json:"invalid"`
+}
+
+func TestVectors(t *testing.T) {
+
+	file, err := os.ReadFile("testdata/vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vectors testVectors
+	if err = json.Unmarshal(file, &vectors); err != nil {
+		t.Fatal(err)
+	}
+
+	if vectors.HRP != Codec.HRP || vectors.Charset != Codec.Charset {
+		t.Fatalf(
+			"vectors are for HRP '%s' and charset '%s'",
+			vectors.HRP, vectors.Charset,
+		)
+	}
+
+	if len(vectors.Valid) < 1 || len(vectors.Invalid) < 1 {
+		t.Fatal("no vectors found")
+	}
+
+	v1, err := NewCodec(Codec.Name, charset, Codec.HRP, WithVersion(Version1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoders := map[int]func([]byte) (string, error){
+		Version0: Codec.Encode,
+		Version1: v1.Encode,
+	}
+
+	for _, v := range vectors.Valid {
+
+		input, err := hex.DecodeString(v.Hex)
+		if err != nil {
+			t.Fatalf("%s: %v", v.Description, err)
+		}
+
+		decoded, err := Codec.Decode(v.Encoded)
+		if err != nil {
+			t.Fatalf("%s: decoding '%s': %v", v.Description, v.Encoded, err)
+		}
+		if string(decoded) != string(input) {
+			t.Fatalf(
+				"%s: '%s' decoded to %x expected %s",
+				v.Description, v.Encoded, decoded, v.Hex,
+			)
+		}
+
+		if v.DecodeOnly {
+			continue
+		}
+
+		encode, ok := encoders[v.Version]
+		if !ok {
+			t.Fatalf("%s: unknown version %d", v.Description, v.Version)
+		}
+
+		encoded, err := encode(input)
+		if err != nil {
+			t.Fatalf("%s: encoding %s: %v", v.Description, v.Hex, err)
+		}
+		if encoded != v.Encoded {
+			t.Fatalf(
+				"%s: %s encoded to '%s' expected '%s'",
+				v.Description, v.Hex, encoded, v.Encoded,
+			)
+		}
+	}
+
+	for _, v := range vectors.Invalid {
+
+		code, ok := proto.Error_value[v.Error]
+		if !ok {
+			t.Fatalf("%s: unknown error %s", v.Description, v.Error)
+		}
+
+		decoded, err := Codec.Decode(v.Encoded)
+		if !errors.Is(err, proto.Error(code)) {
+			t.Fatalf(
+				"%s: '%s' gave %x and error %v expected %s",
+				v.Description, v.Encoded, decoded, err, v.Error,
+			)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package based32
+
+import (
+	"github.com/quanterall/kitchensink/pkg/proto"
+)
+
+// The format version is carried in the first byte of the encoded data, along
+// with the check length.
+//
+// Version 0 is the original format, where the first byte is just the check
+// length. As the check length is never more than 7, the top 5 bits of this
+// byte are always zero, which is why the first character of its encoding can
+// be left out of the string.
+//
+// From version 1 onwards, the top 5 bits of the first byte hold the version,
+// and the bottom 3 bits hold one less than the check length, as a check length
+// of zero is never used. The first character is therefore never zero, and it
+// is kept in the string. So the two layouts can be told apart by the length of
+// the data part of the string alone: version 0 is one character short of a
+// multiple of 8, and later versions are an exact multiple of 8.
+//
+// Version 1 also covers the first byte with the check, so that a change to
+// the version or check length can't go unnoticed.
+//
+// The top bit of the first byte is the compression flag, which is set when the
+// data was compressed by a codec made WithCompression. This leaves 4 bits for
+// the version, and as the flag can't be set in version 0, where the top bits
+// must be zero, compression needs version 1 or later. Decoders from before the
+// flag was added read it as a version above 15, so they refuse compressed
+// strings with UNSUPPORTED_VERSION rather than returning the compressed bytes.
+const (
+
+	// Version0 is the original format, without a version in the encoding.
+	Version0 = 0
+
+	// Version1 is the first format with the version in the encoding.
+	Version1 = 1
+
+	// LatestVersion is the newest version this package can decode.
+	LatestVersion = Version1
+)
+
+// compressedFlag is the bit of the first byte that is set when the data is
+// compressed.
+const compressedFlag = 0x80
+
+// makeHeader returns the first byte of the encoded data for a version, check
+// length and whether the data is compressed.
+func makeHeader(version, checkLen int, compressed bool) (header byte) {
+
+	if version == Version0 {
+
+		return byte(checkLen)
+	}
+
+	header = byte(version<<3 | (checkLen - 1))
+	if compressed {
+
+		header |= compressedFlag
+	}
+
+	return
+}
+
+// maxHeaderCheckLen returns the longest check length that the header of a
+// version can hold. In version 0 the top 5 bits of the header must be zero,
+// and later versions hold one less than the check length in 3 bits.
+func maxHeaderCheckLen(version int) int {
+
+	if version == Version0 {
+
+		return 7
+	}
+
+	return 8
+}
+
+// maxMinCheckLen returns the largest minimum check length that a version can
+// guarantee. Check lengths are made longer 5 bytes at a time, so the check
+// can be up to 4 bytes over the minimum.
+func maxMinCheckLen(version int) int {
+
+	return maxHeaderCheckLen(version) - 4
+}
+
+// parseHeader returns the version, check length and compression flag held by
+// the first byte of the decoded data.
+func parseHeader(header byte) (version, checkLen int, compressed bool) {
+
+	compressed = header&compressedFlag != 0
+	version = int(header >> 3 & 0x0f)
+	if version == Version0 && !compressed {
+
+		checkLen = int(header)
+		return
+	}
+
+	checkLen = int(header&7) + 1
+
+	return
+}
+
+// checkVersion returns an error if the version can't be decoded by this
+// package, or if it is version 0 with the compression flag set, which can't
+// be encoded.
+func checkVersion(version int, compressed bool) (err error) {
+
+	if version > LatestVersion || version == Version0 && compressed {
+
+		err = proto.Error_UNSUPPORTED_VERSION
+	}
+
+	return
+}
@@ -0,0 +1,75 @@
+package based32
+
+import (
+	"bytes"
+	"encoding/base32"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"lukechampine.com/blake3"
+	"testing"
+)
+
+func TestVersions(t *testing.T) {
+
+	v1, err := NewCodec("Version1", charset, "QNTRL", WithVersion(Version1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last := blake3.Sum256([]byte("versions"))
+	for i := 1; i <= 64; i++ {
+
+		input := append(last[:], last[:]...)[:i]
+		last = blake3.Sum256(last[:])
+
+		var encoded string
+		if encoded, err = v1.Encode(input); err != nil {
+			t.Fatal(err)
+		}
+
+		// Version 1 uses the whole of the first character.
+		if (len(encoded)-len(v1.HRP))%8 != 0 {
+			t.Fatalf("'%s' is not a multiple of 8 long", encoded)
+		}
+
+		var original string
+		if original, err = Codec.Encode(input); err != nil {
+			t.Fatal(err)
+		}
+
+		// Both codecs must decode both versions.
+		for _, s := range []string{encoded, original} {
+
+			for _, decode := range []func(string) ([]byte, error){
+				Codec.Decode, v1.Decode,
+			} {
+
+				var decoded []byte
+				if decoded, err = decode(s); err != nil {
+					t.Fatalf("'%s': %v", s, err)
+				}
+				if !bytes.Equal(decoded, input) {
+					t.Fatalf("got %x expected %x", decoded, input)
+				}
+			}
+		}
+	}
+
+	// Make a string with a version from the future, with a valid check.
+	input := []byte("from the future")
+	checkLen := getCheckLen(len(input))
+	data := append([]byte{makeHeader(LatestVersion+1, checkLen, false)}, input...)
+	sum := blake3Check(data)
+	data = append(data, sum[:checkLen]...)
+	future := v1.HRP + base32.NewEncoding(charset).EncodeToString(data)
+
+	if _, err = v1.Decode(future); !errors.Is(err, proto.Error_UNSUPPORTED_VERSION) {
+		t.Fatalf("'%s' gave error %v", future, err)
+	}
+
+	if _, err = NewCodec(
+		"Future", charset, "QNTRL", WithVersion(LatestVersion+1),
+	); err == nil {
+		t.Fatal("codec created with unsupported version")
+	}
+}
@@ -0,0 +1,490 @@
+// Package bech32 provides a standards conformant implementation of the BIP 0173
+// Bech32 and BIP 0350 Bech32m human readable binary codecs
+//
+// Unlike the based32 package, which borrows only the character set from Bech32,
+// this package implements the real BCH code checksum (the "polymod"), the
+// expansion of the Human Readable Part into the checksum, the '1' separator
+// and the Bech32m constant, so the strings it produces and accepts are
+// interoperable with wallets and tools that speak the standard.
+//
+// The low level Encode and Decode functions work with 5 bit groups, exactly as
+// described in the specifications, and New wraps these into a codec.Codec that
+// converts arbitrary bytes, so it can be used anywhere based32.Codec is used.
+package bech32
+
+import (
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"strings"
+)
+
+// Variant selects which of the two checksum constants is used. The checksum
+// is calculated identically for both, the only difference is the value the
+// polymod is XORed with at the end.
+type Variant uint32
+
+const (
+
+	// Invalid is returned by Decode when the checksum matches neither variant.
+	Invalid Variant = 0
+
+	// Bech32 is the original BIP 0173 checksum constant.
+	Bech32 Variant = 1
+
+	// Bech32m is the BIP 0350 checksum constant, which fixes the weakness of
+	// the original with respect to insertion of 'q' characters before a
+	// trailing 'p'.
+	Bech32m Variant = 0x2bc830a3
+)
+
+// String returns the name of the variant, as used in the specifications.
+func (v Variant) String() string {
+
+	switch v {
+	case Bech32:
+		return "Bech32"
+	case Bech32m:
+		return "Bech32m"
+	default:
+		return "Invalid"
+	}
+}
+
+const (
+
+	// charset is the same as used in the based32 package, as it is the one
+	// defined by BIP 0173. For a given charset[i], i is the 5 bit value of the
+	// character.
+	charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	// separator divides the Human Readable Part from the data. It is the last
+	// occurrence of the character that counts, as it may appear in the HRP.
+	separator = '1'
+
+	// checksumLen is the number of 5 bit characters in the checksum.
+	checksumLen = 6
+
+	// maxLength is the maximum total length of a string permitted by both
+	// specifications.
+	maxLength = 90
+
+	// maxHRPLength is the maximum length of the Human Readable Part.
+	maxHRPLength = 83
+)
+
+// charsetRev maps an ASCII character back to its 5 bit value, with -1 marking
+// characters that are not in the charset. It is generated from charset so the
+// two can never disagree.
+var charsetRev = func() (rev [128]int8) {
+
+	for i := range rev {
+
+		rev[i] = -1
+	}
+
+	for i := range charset {
+
+		rev[charset[i]] = int8(i)
+	}
+
+	return
+}()
+
+// generator contains the coefficients of the BCH code generator polynomial.
+var generator = [5]uint32{
+	0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3,
+}
+
+// polymod computes the BCH checksum over a sequence of 5 bit values. This is
+// a direct translation of the reference implementation in BIP 0173.
+func polymod(values []byte) (chk uint32) {
+
+	chk = 1
+	for _, v := range values {
+
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+
+			if (top>>uint(i))&1 == 1 {
+
+				chk ^= generator[i]
+			}
+		}
+	}
+
+	return
+}
+
+// hrpExpand splits each character of the Human Readable Part into its high 3
+// bits and low 5 bits, with a zero between the two halves, so that the HRP is
+// covered by the checksum.
+func hrpExpand(hrp string) (output []byte) {
+
+	output = make([]byte, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+
+		output[i] = hrp[i] >> 5
+		output[i+len(hrp)+1] = hrp[i] & 31
+	}
+
+	return
+}
+
+// createChecksum returns the 6 checksum characters (as 5 bit values) for the
+// given HRP and data with the constant of the requested variant.
+func createChecksum(hrp string, data []byte, variant Variant) (
+	output []byte,
+) {
+
+	values := append(hrpExpand(hrp), data...)
+	values = append(values, make([]byte, checksumLen)...)
+	mod := polymod(values) ^ uint32(variant)
+
+	output = make([]byte, checksumLen)
+	for i := range output {
+
+		output[i] = byte(mod>>uint(5*(5-i))) & 31
+	}
+
+	return
+}
+
+// verifyChecksum returns the variant whose constant the checksum of the data
+// (which must include the checksum characters) matches, or Invalid.
+func verifyChecksum(hrp string, data []byte) (variant Variant) {
+
+	switch Variant(polymod(append(hrpExpand(hrp), data...))) {
+	case Bech32:
+		return Bech32
+	case Bech32m:
+		return Bech32m
+	}
+
+	return Invalid
+}
+
+// validHRP checks the HRP is of a permitted length and only contains the
+// printable ASCII characters 33 to 126.
+func validHRP(hrp string) (err error) {
+
+	if len(hrp) < 1 || len(hrp) > maxHRPLength {
+
+		err = proto.Error_INCORRECT_HUMAN_READABLE_PART
+		return
+	}
+
+	for i := 0; i < len(hrp); i++ {
+
+		if hrp[i] < 33 || hrp[i] > 126 {
+
+			err = proto.Error_INCORRECT_HUMAN_READABLE_PART
+			return
+		}
+	}
+
+	return
+}
+
+// Encode produces a Bech32 or Bech32m string from a Human Readable Part and
+// data given as 5 bit values. The output is always lower case, as the
+// specification requires, callers wanting upper case, for example for QR codes,
+// can convert the whole string with strings.ToUpper.
+func Encode(hrp string, data []byte, variant Variant) (
+	output string, err error,
+) {
+
+	if err = validHRP(hrp); err != nil {
+		return
+	}
+
+	if variant != Bech32 && variant != Bech32m {
+
+		err = proto.Error_CHECK_FAILED
+		return
+	}
+
+	// Mixed case HRPs are not permitted, and the checksum is always computed on
+	// the lower case form.
+	if strings.ToLower(hrp) != hrp && strings.ToUpper(hrp) != hrp {
+
+		err = proto.Error_MIXED_CASE
+		return
+	}
+	hrp = strings.ToLower(hrp)
+
+	if len(hrp)+1+len(data)+checksumLen > maxLength {
+
+		err = proto.Error_INVALID_LENGTH
+		return
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(hrp) + 1 + len(data) + checksumLen)
+	sb.WriteString(hrp)
+	sb.WriteByte(separator)
+
+	for _, v := range data {
+
+		if v > 31 {
+
+			err = proto.Error_INVALID_CHARACTER
+			return
+		}
+		sb.WriteByte(charset[v])
+	}
+
+	for _, v := range createChecksum(hrp, data, variant) {
+
+		sb.WriteByte(charset[v])
+	}
+
+	output = sb.String()
+
+	return
+}
+
+// Decode splits a Bech32 or Bech32m string into its Human Readable Part and
+// data as 5 bit values, with the checksum removed, and reports which variant's
+// checksum it carries.
+//
+// The HRP is returned in lower case. Strings that are entirely upper case are
+// accepted, but mixed case strings are rejected.
+func Decode(input string) (
+	hrp string, data []byte, variant Variant, err error,
+) {
+
+	if len(input) > maxLength {
+
+		err = proto.Error_INVALID_LENGTH
+		return
+	}
+
+	// Only printable ASCII is permitted anywhere in the string, checking this
+	// first also means the case conversions below can't be confused by
+	// invalid UTF-8.
+	for i := 0; i < len(input); i++ {
+
+		if input[i] < 33 || input[i] > 126 {
+
+			err = proto.Error_INVALID_CHARACTER
+			return
+		}
+	}
+
+	lower, upper := strings.ToLower(input), strings.ToUpper(input)
+	if input != lower && input != upper {
+
+		err = proto.Error_MIXED_CASE
+		return
+	}
+	input = lower
+
+	// The separator is the last '1' in the string, as the HRP may contain them
+	// but the data part can't.
+	sep := strings.LastIndexByte(input, separator)
+	switch {
+	case sep < 0:
+
+		err = proto.Error_MISSING_SEPARATOR
+		return
+
+	case sep < 1:
+
+		// There must be at least one character of HRP.
+		err = proto.Error_INCORRECT_HUMAN_READABLE_PART
+		return
+
+	case sep+checksumLen+1 > len(input):
+
+		// There must be at least a full checksum after the separator.
+		err = proto.Error_CHECK_TOO_SHORT
+		return
+	}
+
+	hrp = input[:sep]
+	if err = validHRP(hrp); err != nil {
+		return
+	}
+
+	values := make([]byte, len(input)-sep-1)
+	for i := range values {
+
+		c := input[sep+1+i]
+		if charsetRev[c] < 0 {
+
+			err = proto.Error_INVALID_CHARACTER
+			return
+		}
+		values[i] = byte(charsetRev[c])
+	}
+
+	if variant = verifyChecksum(hrp, values); variant == Invalid {
+
+		err = proto.Error_CHECK_FAILED
+		return
+	}
+
+	data = values[:len(values)-checksumLen]
+
+	return
+}
+
+// ConvertBits regroups a sequence of values of fromBits width into values of
+// toBits width. With pad set, any remaining bits are zero padded into a final
+// value, which is what is used for encoding. Without, the leftover bits must
+// be fewer than fromBits and all zero, which is the strict rule for decoding.
+func ConvertBits(data []byte, fromBits, toBits uint, pad bool) (
+	output []byte, err error,
+) {
+
+	var acc uint32
+	var bits uint
+	maxv := uint32(1)<<toBits - 1
+
+	output = make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, v := range data {
+
+		if uint32(v)>>fromBits != 0 {
+
+			err = proto.Error_INVALID_CHARACTER
+			return
+		}
+
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+
+			bits -= toBits
+			output = append(output, byte(acc>>bits&maxv))
+		}
+	}
+
+	switch {
+	case pad:
+
+		if bits > 0 {
+
+			output = append(output, byte(acc<<(toBits-bits)&maxv))
+		}
+
+	case bits >= fromBits || acc<<(toBits-bits)&maxv != 0:
+
+		err = proto.Error_INVALID_PADDING
+		output = nil
+	}
+
+	return
+}
+
+// New creates a codec.Codec that encodes arbitrary bytes as a standard Bech32
+// or Bech32m string with the given Human Readable Part.
+//
+// The HRP is stored in lower case as that is the canonical form. Because of the
+// 90 character limit of the standard, at most 40 bytes can be encoded with a
+// single character HRP, and less as the HRP gets longer.
+func New(name, hrp string, variant Variant) (cdc *codec.Codec, err error) {
+
+	if err = validHRP(hrp); err != nil {
+		return
+	}
+
+	if variant != Bech32 && variant != Bech32m {
+
+		err = proto.Error_CHECK_FAILED
+		return
+	}
+
+	cdc = &codec.Codec{
+		Name:      name,
+		Charset:   charset,
+		HRP:       strings.ToLower(hrp),
+		CheckName: variant.String(),
+	}
+
+	// The checksum of Bech32 has a fixed length and covers the HRP, so the
+	// check length parameter is ignored, and the input is the 5 bit values of
+	// the data part.
+	cdc.MakeCheck = func(input []byte, _ int) (output []byte) {
+
+		return createChecksum(cdc.HRP, input, variant)
+	}
+
+	// Check expects the 5 bit values of the data part followed by the 6
+	// checksum values, as found in the string after the separator.
+	cdc.Check = func(input []byte) (err error) {
+
+		switch {
+		case input == nil:
+
+			err = proto.Error_NIL_SLICE
+			return
+
+		case len(input) < checksumLen:
+
+			err = proto.Error_CHECK_TOO_SHORT
+			return
+		}
+
+		if verifyChecksum(cdc.HRP, input) != variant {
+
+			err = proto.Error_CHECK_FAILED
+		}
+
+		return
+	}
+
+	cdc.Encoder = func(input []byte) (output string, err error) {
+
+		if len(input) < 1 {
+
+			err = proto.Error_ZERO_LENGTH
+			return
+		}
+
+		var data []byte
+		if data, err = ConvertBits(input, 8, 5, true); err != nil {
+			return
+		}
+
+		return Encode(cdc.HRP, data, variant)
+	}
+
+	cdc.Decoder = func(input string) (output []byte, err error) {
+
+		var hrp string
+		var data []byte
+		var v Variant
+		if hrp, data, v, err = Decode(input); err != nil {
+			return
+		}
+
+		if hrp != cdc.HRP {
+
+			err = proto.Error_INCORRECT_HUMAN_READABLE_PART
+			return
+		}
+
+		// A valid checksum of the other variant is still the wrong checksum
+		// for this codec.
+		if v != variant {
+
+			err = proto.Error_CHECK_FAILED
+			return
+		}
+
+		if output, err = ConvertBits(data, 5, 8, false); err != nil {
+			return
+		}
+
+		if len(output) < 1 {
+
+			output = nil
+			err = proto.Error_ZERO_LENGTH
+		}
+
+		return
+	}
+
+	return
+}
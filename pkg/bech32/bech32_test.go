@@ -0,0 +1,259 @@
+package bech32
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/quanterall/kitchensink/pkg/codecer/codecertest"
+	"lukechampine.com/blake3"
+	"strings"
+	"testing"
+)
+
+// The following vectors are taken from the test vector sections of BIP 0173
+// and BIP 0350.
+
+var validBech32 = []string{
+	"A12UEL5L",
+	"a12uel5l",
+	"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+	"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+	"11qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqc8247j",
+	"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+	"?1ezyfcl",
+}
+
+var validBech32m = []string{
+	"A1LQFN3A",
+	"a1lqfn3a",
+	"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6",
+	"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx",
+	"11llllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllludsr8",
+	"split1checkupstagehandshakeupstreamerranterredcaperredlc445v",
+	"?1v759aa",
+}
+
+var invalidBech32 = []string{
+	"\x201nwldj5",
+	"\x7f1axkwrx",
+	"\x801eym55h",
+	"an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx",
+	"pzry9x0s0muk",
+	"1pzry9x0s0muk",
+	"x1b4n0q5v",
+	"li1dgmt3",
+	"de1lg7wt\xff",
+	"A1G7SGD8",
+	"10a06t8",
+	"1qzzfhee",
+}
+
+var invalidBech32m = []string{
+	"\x201xj0phk",
+	"\x7f1g6xzxy",
+	"\x801vctc34",
+	"an84characterslonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11d6pts4",
+	"qyrz8wqd2c9m",
+	"1qyrz8wqd2c9m",
+	"y1b0jsk6g",
+	"lt1igcx5c0",
+	"in1muywd",
+	"mm1crxm3i",
+	"au1s5cgom",
+	"M1VUXWEZ",
+	"16plkw9",
+	"1p2gdwpf",
+}
+
+func TestChecksums(t *testing.T) {
+
+	for variant, vectors := range map[Variant][]string{
+		Bech32:  validBech32,
+		Bech32m: validBech32m,
+	} {
+
+		for _, v := range vectors {
+
+			hrp, data, found, err := Decode(v)
+			if err != nil {
+				t.Fatalf("%s: '%s' failed to decode: %v", variant, v, err)
+			}
+			if found != variant {
+				t.Fatalf("%s: '%s' decoded as %s", variant, v, found)
+			}
+
+			// Re-encoding must reproduce the lower case form of the input.
+			var encoded string
+			if encoded, err = Encode(hrp, data, variant); err != nil {
+				t.Fatal(err)
+			}
+			if encoded != strings.ToLower(v) {
+				t.Fatalf("%s: expected '%s' got '%s'", variant, v, encoded)
+			}
+
+			// Flipping a character of the data part must break the checksum.
+			sep := strings.LastIndexByte(v, separator)
+			broken := []byte(strings.ToLower(v))
+			pos := sep + 1 + (len(broken)-sep-1)/2
+			broken[pos] = charset[(charsetRev[broken[pos]]+1)%32]
+			if _, _, _, err = Decode(string(broken)); err == nil {
+				t.Fatalf("%s: corrupted '%s' decoded", variant, broken)
+			}
+		}
+	}
+
+	for variant, vectors := range map[Variant][]string{
+		Bech32:  invalidBech32,
+		Bech32m: invalidBech32m,
+	} {
+
+		for _, v := range vectors {
+
+			if _, _, found, err := Decode(v); err == nil && found == variant {
+				t.Fatalf("%s: invalid '%q' decoded", variant, v)
+			}
+		}
+	}
+}
+
+// TestSegwitAddresses decodes addresses from the BIP 0350 vectors into their
+// witness version and program and compares them with the expected output
+// scripts.
+func TestSegwitAddresses(t *testing.T) {
+
+	vectors := []struct {
+		address, script string
+		variant         Variant
+	}{
+		{
+			"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4",
+			"0014751e76e8199196d454941c45d1b3a323f1433bd6",
+			Bech32,
+		},
+		{
+			"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7",
+			"00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262",
+			Bech32,
+		},
+		{
+			"bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y",
+			"5128751e76e8199196d454941c45d1b3a323f1433bd6751e76e8199196d454941c45d1b3a323f1433bd6",
+			Bech32m,
+		},
+		{
+			"BC1SW50QGDZ25J",
+			"6002751e",
+			Bech32m,
+		},
+	}
+
+	for _, v := range vectors {
+
+		_, data, variant, err := Decode(v.address)
+		if err != nil {
+			t.Fatalf("'%s' failed to decode: %v", v.address, err)
+		}
+		if variant != v.variant {
+			t.Fatalf("'%s' decoded as %s", v.address, variant)
+		}
+
+		var program []byte
+		if program, err = ConvertBits(data[1:], 5, 8, false); err != nil {
+			t.Fatal(err)
+		}
+
+		// Version 0 is OP_0, the others are OP_1 to OP_16.
+		version := data[0]
+		if version > 0 {
+
+			version += 0x50
+		}
+
+		script := append([]byte{version, byte(len(program))}, program...)
+		if hex.EncodeToString(script) != v.script {
+			t.Fatalf(
+				"'%s' got script %x expected %s", v.address, script, v.script,
+			)
+		}
+	}
+}
+
+func TestCodec(t *testing.T) {
+
+	for _, variant := range []Variant{Bech32, Bech32m} {
+
+		cdc, err := New("Bech32", "QNTRL", variant)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Encode a hash chain of all the lengths that fit in the 90 character
+		// limit with this HRP.
+		last := blake3.Sum256([]byte(variant.String()))
+		for i := 1; i <= 48; i++ {
+
+			input := last[:i%32+1]
+			if i > 32 {
+
+				input = append(last[:], last[:i-32]...)
+			}
+
+			var encoded string
+			if encoded, err = cdc.Encode(input); err != nil {
+				t.Fatalf("%s: length %d: %v", variant, len(input), err)
+			}
+			if !strings.HasPrefix(encoded, "qntrl1") {
+				t.Fatalf("%s: unexpected prefix '%s'", variant, encoded)
+			}
+
+			var decoded []byte
+			if decoded, err = cdc.Decode(strings.ToUpper(encoded)); err != nil {
+				t.Fatalf("%s: '%s': %v", variant, encoded, err)
+			}
+			if !bytes.Equal(input, decoded) {
+				t.Fatalf("%s: got %x expected %x", variant, decoded, input)
+			}
+
+			last = blake3.Sum256(last[:])
+		}
+
+		if _, err = cdc.Encode(nil); err == nil {
+			t.Fatalf("%s: encoded empty input", variant)
+		}
+	}
+
+	// A string of one variant must not be accepted by the codec for the
+	// other, even though its checksum is valid.
+	b32, _ := New("Bech32", "qntrl", Bech32)
+	b32m, _ := New("Bech32m", "qntrl", Bech32m)
+	encoded, err := b32.Encode([]byte("kitchensink"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = b32m.Decode(encoded); err == nil {
+		t.Fatalf("Bech32m codec accepted Bech32 string '%s'", encoded)
+	}
+}
+
+func TestConformance(t *testing.T) {
+
+	for _, variant := range []Variant{Bech32, Bech32m} {
+
+		cdc, err := New(variant.String(), "kitchen", variant)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Encoded strings can be at most 90 characters long, which with this
+		// HRP leaves room for 47 bytes of data.
+		lengths := make([]int, 40)
+		for i := range lengths {
+
+			lengths[i] = i + 1
+		}
+
+		t.Run(variant.String(), func(t *testing.T) {
+
+			codecertest.RunLengths(t, cdc, lengths)
+		})
+	}
+}
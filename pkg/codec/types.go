@@ -53,6 +53,22 @@ type Codec struct {
 	// the value passes any check function defined for the type.
 	Decoder func(input string) (output []byte, err error)
 
+	// AppendEncoder appends the encoding of src to dst and returns the extended
+	// buffer, in the same way as the standard library strconv.Append*
+	// functions. Given a dst with enough spare capacity, it need not allocate
+	// at all. This can be nil, in which case AppendEncode falls back to
+	// Encoder.
+	AppendEncoder func(dst, src []byte) (output []byte, err error)
+
+	// AppendDecoder appends the decoding of the encoded src to dst and returns
+	// the extended buffer, as for AppendEncoder. This can be nil, in which case
+	// AppendDecode falls back to Decoder.
+	AppendDecoder func(dst, src []byte) (output []byte, err error)
+
+	// CheckName is the name of the algorithm used by MakeCheck, so users of
+	// the codec can tell which one was chosen when it was constructed.
+	CheckName string
+
 	// AddCheck is used by Encode to add extra bytes for the checksum to ensure
 	// correct input so user does not send to a wrong address by mistake, for
 	// example.
@@ -100,3 +116,44 @@ func (c *Codec) Encode(input []byte) (string, error) { return c.Encoder(input) }
 // type definition and interface, omitting them here makes the line short enough
 // to be a one liner.
 func (c *Codec) Decode(input string) ([]byte, error) { return c.Decoder(input) }
+
+// AppendEncode appends the encoding of src to dst and returns the extended
+// buffer, using AppendEncoder if the codec provides one and Encoder otherwise.
+//
+// If there is an error, the returned buffer is dst as it was given.
+func (c *Codec) AppendEncode(dst, src []byte) (output []byte, err error) {
+
+	if c.AppendEncoder != nil {
+
+		return c.AppendEncoder(dst, src)
+	}
+
+	var s string
+	if s, err = c.Encoder(src); err != nil {
+
+		return dst, err
+	}
+
+	return append(dst, s...), nil
+}
+
+// AppendDecode appends the decoding of the encoded src to dst and returns the
+// extended buffer, using AppendDecoder if the codec provides one and Decoder
+// otherwise.
+//
+// If there is an error, the returned buffer is dst as it was given.
+func (c *Codec) AppendDecode(dst, src []byte) (output []byte, err error) {
+
+	if c.AppendDecoder != nil {
+
+		return c.AppendDecoder(dst, src)
+	}
+
+	var b []byte
+	if b, err = c.Decoder(string(src)); err != nil {
+
+		return dst, err
+	}
+
+	return append(dst, b...), nil
+}
@@ -0,0 +1,269 @@
+// Package codecertest provides a conformance test suite for implementations of
+// codecer.Codecer, so that every implementation can be held to the same
+// contract without copying the tests of another.
+//
+// This follows the pattern of the standard library testing/fstest and
+// testing/iotest packages: an ordinary package, not a _test.go file, so that it
+// can be imported by the tests of other packages, named after the package it
+// tests with the suffix "test".
+//
+// A typical use in a test of a codec is simply:
+//
+//	func TestConformance(t *testing.T) {
+//		codecertest.Run(t, myCodec)
+//	}
+package codecertest
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/codecer"
+	"lukechampine.com/blake3"
+	"testing"
+)
+
+// MaxLength is the longest input that Run tests round trips with. Codecs that
+// can't encode this much, such as those with a limit on the length of the
+// encoded string, can use RunLengths instead.
+const MaxLength = 64
+
+// Run runs the conformance suite against the codec, with inputs of every
+// length from 1 to MaxLength bytes. The checks are run as subtests, so they can
+// be selected individually with the -run flag of go test.
+func Run(t *testing.T, c codecer.Codecer) {
+
+	lengths := make([]int, MaxLength)
+	for i := range lengths {
+
+		lengths[i] = i + 1
+	}
+
+	RunLengths(t, c, lengths)
+}
+
+// RunLengths runs the conformance suite against the codec with inputs of the
+// given lengths, which must all be at least 1.
+//
+// The codec must:
+//
+//   - decode its encoding of any input to the same input
+//   - always encode the same input to the same string, and decode the same
+//     string to the same output
+//   - return an error when encoding empty or nil input, and when decoding an
+//     empty string
+//   - return an error when decoding its encodings with a character replaced by
+//     another, with two neighbouring characters swapped, or with a character
+//     added or removed at the end.
+//
+// Only letters and digits are replaced and swapped, and changing only the case
+// of a letter is not counted as a mutation, so codecs that ignore separators or
+// case still pass.
+func RunLengths(t *testing.T, c codecer.Codecer, lengths []int) {
+
+	inputs := makeInputs(lengths)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+
+		for _, input := range inputs {
+
+			encoded := encode(t, c, input)
+
+			decoded, err := c.Decode(encoded)
+			if err != nil {
+				t.Fatalf("decoding '%s' of %x: %v", encoded, input, err)
+			}
+
+			if !bytes.Equal(decoded, input) {
+				t.Fatalf(
+					"decoding '%s' gave %x expected %x", encoded, decoded, input,
+				)
+			}
+		}
+	})
+
+	t.Run("Deterministic", func(t *testing.T) {
+
+		for _, input := range inputs {
+
+			encoded := encode(t, c, input)
+			if again := encode(t, c, input); again != encoded {
+				t.Fatalf(
+					"encoding %x gave '%s' then '%s'", input, encoded, again,
+				)
+			}
+
+			decoded, err := c.Decode(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			again, err := c.Decode(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(decoded, again) {
+				t.Fatalf(
+					"decoding '%s' gave %x then %x", encoded, decoded, again,
+				)
+			}
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+
+		if encoded, err := c.Encode([]byte{}); err == nil {
+			t.Errorf("encoding empty input gave '%s' and no error", encoded)
+		}
+
+		if encoded, err := c.Encode(nil); err == nil {
+			t.Errorf("encoding nil input gave '%s' and no error", encoded)
+		}
+
+		if decoded, err := c.Decode(""); err == nil {
+			t.Errorf("decoding empty string gave %x and no error", decoded)
+		}
+	})
+
+	t.Run("Corrupted", func(t *testing.T) {
+
+		for _, input := range inputs {
+
+			encoded := encode(t, c, input)
+			last := encoded[len(encoded)-1:]
+
+			for _, corrupted := range []string{
+				encoded[:len(encoded)-1],
+				encoded + last,
+				swapped(encoded),
+			} {
+
+				if corrupted == "" || corrupted == encoded {
+					continue
+				}
+
+				rejected(t, c, encoded, corrupted)
+			}
+		}
+	})
+
+	t.Run("Mutations", func(t *testing.T) {
+
+		for _, input := range inputs {
+
+			encoded := encode(t, c, input)
+
+			for i := 0; i < len(encoded); i++ {
+
+				if mutated, ok := mutate(encoded, i); ok {
+
+					rejected(t, c, encoded, mutated)
+				}
+			}
+		}
+	})
+}
+
+// makeInputs creates deterministic inputs of the given lengths from a chain of
+// hashes, each hash being of the one before, so that the same inputs are used
+// every time the suite is run.
+func makeInputs(lengths []int) (inputs [][]byte) {
+
+	hash := blake3.Sum256([]byte("codecertest"))
+
+	for _, l := range lengths {
+
+		input := make([]byte, 0, l)
+		for len(input) < l {
+
+			hash = blake3.Sum256(hash[:])
+			input = append(input, hash[:]...)
+		}
+
+		inputs = append(inputs, input[:l])
+	}
+
+	return
+}
+
+// encode encodes the input, failing the test if there is an error.
+func encode(t *testing.T, c codecer.Codecer, input []byte) (encoded string) {
+
+	t.Helper()
+
+	encoded, err := c.Encode(input)
+	if err != nil {
+		t.Fatalf("encoding %x: %v", input, err)
+	}
+
+	if encoded == "" {
+		t.Fatalf("encoding %x gave an empty string", input)
+	}
+
+	return
+}
+
+// rejected fails the test if the corrupted version of the encoded string
+// decodes without an error.
+func rejected(t *testing.T, c codecer.Codecer, encoded, corrupted string) {
+
+	t.Helper()
+
+	if decoded, err := c.Decode(corrupted); err == nil {
+		t.Fatalf(
+			"'%s' corrupted to '%s' decoded to %x without error",
+			encoded, corrupted, decoded,
+		)
+	}
+}
+
+// isAlphanumeric returns true if the character is a letter or a digit.
+func isAlphanumeric(c byte) bool {
+
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// sameLetter returns true if the characters are the same apart from their case.
+func sameLetter(a, b byte) bool {
+
+	return bytes.EqualFold([]byte{a}, []byte{b})
+}
+
+// mutate replaces the character at the given position with the next different
+// character found in the string after it, wrapping around at the end, as only
+// characters from the string itself are sure to be ones the codec uses. It
+// returns false if there is no suitable character.
+func mutate(s string, pos int) (mutated string, ok bool) {
+
+	if !isAlphanumeric(s[pos]) {
+		return
+	}
+
+	for i := 1; i < len(s); i++ {
+
+		c := s[(pos+i)%len(s)]
+		if isAlphanumeric(c) && !sameLetter(c, s[pos]) {
+
+			mutated = fmt.Sprintf("%s%c%s", s[:pos], c, s[pos+1:])
+			return mutated, true
+		}
+	}
+
+	return
+}
+
+// swapped returns the string with the last pair of neighbouring characters that
+// are different swapped, or the string unchanged if there is no such pair.
+func swapped(s string) string {
+
+	for i := len(s) - 1; i > 0; i-- {
+
+		a, b := s[i-1], s[i]
+		if isAlphanumeric(a) && isAlphanumeric(b) && !sameLetter(a, b) {
+
+			return s[:i-1] + string(b) + string(a) + s[i+1:]
+		}
+	}
+
+	return s
+}
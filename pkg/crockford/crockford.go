@@ -0,0 +1,277 @@
+// Package crockford provides codecs for Douglas Crockford's Base32, as
+// described at https://www.crockford.com/base32.html, with and without its
+// optional check symbol.
+//
+// The alphabet leaves out the letters 'I', 'L' and 'O', which are read as '1',
+// '1' and '0' when decoding, along with 'U', so that codes can't accidentally
+// spell common obscenities. Codes are decoded in either case, and hyphens,
+// which can be used to break up long codes, are ignored.
+//
+// Crockford's specification encodes a number. Bytes are encoded five bits to
+// a symbol, most significant first, with the last symbol filled up with zero
+// bits, which is the same as the standard library encoding/base32 package
+// with this alphabet and without padding, and the same as used by ULID.
+//
+// The check symbol is the value of the number the symbols stand for, modulo
+// 37, which being prime and larger than 32 catches every change of a single
+// symbol, and every swap of two neighbouring symbols. The values 32 to 36 are
+// written with the extra symbols '*', '~', '$', '=' and 'U'.
+package crockford
+
+import (
+	"github.com/quanterall/kitchensink/pkg/bech32"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"strings"
+)
+
+const (
+
+	// Alphabet is the 32 symbols used for the data, in the order of their
+	// values.
+	Alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+	// CheckSymbols are the symbols for the check values from 32 to 36, which
+	// only appear as the check symbol.
+	CheckSymbols = "*~$=U"
+
+	// modulus is the number the check symbol is the remainder of division by.
+	modulus = 37
+)
+
+// The codecs for Crockford's Base32, without and with the check symbol.
+var (
+	Crockford      = mustNew("Crockford")
+	CrockfordCheck = mustNew("CrockfordCheck", WithCheckSymbol())
+)
+
+// mustNew creates one of the package codecs, whose settings are known to be
+// valid.
+func mustNew(name string, opts ...Option) *codec.Codec {
+
+	cdc, err := New(name, opts...)
+	if err != nil {
+
+		panic(err)
+	}
+
+	return cdc
+}
+
+// values maps each character to the value of the symbol it is read as, with
+// -1 for those that are not symbols. Lower case letters, and the letters that
+// look like '0' and '1', are read as those symbols, as the specification asks.
+var values = func() (values [256]int) {
+
+	for i := range values {
+
+		values[i] = -1
+	}
+
+	symbols := Alphabet + CheckSymbols
+	for i := 0; i < len(symbols); i++ {
+
+		c := symbols[i]
+		values[c] = i
+		if c >= 'A' && c <= 'Z' {
+
+			values[c-'A'+'a'] = i
+		}
+	}
+
+	for _, c := range "Oo" {
+
+		values[c] = 0
+	}
+	for _, c := range "IiLl" {
+
+		values[c] = 1
+	}
+
+	return
+}()
+
+// checkSymbol returns the check value of the symbols, being the number they
+// stand for in base 32, modulo 37.
+func checkSymbol(symbols []byte) (check int) {
+
+	for _, s := range symbols {
+
+		check = (check*32 + int(s)) % modulus
+	}
+
+	return
+}
+
+// options are the settings of a codec that can be changed by an Option.
+type options struct {
+	checkSymbol bool
+}
+
+// Option changes a setting of a codec created by New.
+type Option func(o *options) (err error)
+
+// WithCheckSymbol adds the check symbol to the end of the encoding, which must
+// then be present when decoding.
+func WithCheckSymbol() Option {
+
+	return func(o *options) (err error) {
+
+		o.checkSymbol = true
+
+		return
+	}
+}
+
+// New creates a Crockford Base32 codec with the given name.
+func New(name string, opts ...Option) (cdc *codec.Codec, err error) {
+
+	var o options
+	for _, opt := range opts {
+
+		if err = opt(&o); err != nil {
+			return
+		}
+	}
+
+	cdc = &codec.Codec{
+		Name:    name,
+		Charset: Alphabet,
+	}
+
+	if o.checkSymbol {
+
+		cdc.Charset = Alphabet + CheckSymbols
+		cdc.CheckName = "Mod37"
+
+		// The check is of the 5 bit values of the data, and is always one
+		// symbol long.
+		cdc.MakeCheck = func(input []byte, _ int) (output []byte) {
+
+			return []byte{byte(checkSymbol(input))}
+		}
+
+		// Check expects the 5 bit values of the data followed by the value of
+		// the check symbol.
+		cdc.Check = func(input []byte) (err error) {
+
+			switch {
+			case input == nil:
+
+				err = proto.Error_NIL_SLICE
+				return
+
+			case len(input) < 2:
+
+				err = proto.Error_CHECK_TOO_SHORT
+				return
+			}
+
+			last := len(input) - 1
+			if int(input[last]) != checkSymbol(input[:last]) {
+
+				err = proto.Error_CHECK_FAILED
+			}
+
+			return
+		}
+	}
+
+	cdc.Encoder = func(input []byte) (output string, err error) {
+
+		if len(input) < 1 {
+
+			err = proto.Error_ZERO_LENGTH
+			return
+		}
+
+		var symbols []byte
+		if symbols, err = bech32.ConvertBits(input, 8, 5, true); err != nil {
+			return
+		}
+
+		if o.checkSymbol {
+
+			symbols = append(symbols, cdc.MakeCheck(symbols, 1)...)
+		}
+
+		var sb strings.Builder
+		sb.Grow(len(symbols))
+		for _, s := range symbols {
+
+			sb.WriteByte(cdc.Charset[s])
+		}
+
+		return sb.String(), nil
+	}
+
+	cdc.Decoder = func(input string) (output []byte, err error) {
+
+		symbols := make([]byte, 0, len(input))
+		for i := 0; i < len(input); i++ {
+
+			if input[i] == '-' {
+				continue
+			}
+
+			v := values[input[i]]
+			if v < 0 {
+
+				err = proto.Error_INVALID_CHARACTER
+				return
+			}
+			symbols = append(symbols, byte(v))
+		}
+
+		if len(symbols) < 1 {
+
+			err = proto.Error_ZERO_LENGTH
+			return
+		}
+
+		data := symbols
+		if o.checkSymbol {
+
+			data = symbols[:len(symbols)-1]
+		}
+
+		// Only the check symbol can have the values above 31.
+		for _, s := range data {
+
+			if s >= 32 {
+
+				err = proto.Error_INVALID_CHARACTER
+				return
+			}
+		}
+
+		if o.checkSymbol {
+
+			if err = cdc.Check(symbols); err != nil {
+				return
+			}
+		}
+
+		// The number of symbols must be one that encoding some number of
+		// bytes gives, which leaves fewer than 5 bits over.
+		if len(data)*5%8 >= 5 {
+
+			err = proto.Error_INVALID_LENGTH
+			return
+		}
+
+		if output, err = bech32.ConvertBits(data, 5, 8, false); err != nil {
+			return
+		}
+
+		if len(output) < 1 {
+
+			output = nil
+			err = proto.Error_ZERO_LENGTH
+		}
+
+		return
+	}
+
+	return
+}
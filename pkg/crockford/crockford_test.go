@@ -0,0 +1,135 @@
+package crockford
+
+import (
+	"bytes"
+	"encoding/base32"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/codecer/codecertest"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestEncoding(t *testing.T) {
+
+	// The encoding of bytes is the same as the standard library base32 with
+	// the Crockford alphabet.
+	std := base32.NewEncoding(Alphabet).WithPadding(base32.NoPadding)
+	for l := 1; l <= 64; l++ {
+
+		input := make([]byte, l)
+		rand.Read(input)
+
+		encoded, err := Crockford.Encode(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := std.EncodeToString(input); encoded != expected {
+			t.Fatalf("%x encoded to '%s' expected '%s'", input, encoded, expected)
+		}
+
+		decoded, err := Crockford.Decode(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Fatalf("'%s' decoded to %x expected %x", encoded, decoded, input)
+		}
+	}
+}
+
+func TestCheckSymbol(t *testing.T) {
+
+	// The check is of the number the symbols stand for, so 32 ("10") gives
+	// the first of the extra check symbols.
+	for _, c := range []struct {
+		symbols string
+		check   byte
+	}{
+		{"0", '0'},
+		{"1", '1'},
+		{"10", '*'},
+		{"16J", 'D'},
+		{"ZZZZZZ", 'A'},
+	} {
+
+		values := make([]byte, len(c.symbols))
+		for i := range values {
+
+			values[i] = byte(strings.IndexByte(Alphabet, c.symbols[i]))
+		}
+
+		if check := (Alphabet + CheckSymbols)[checkSymbol(values)]; check !=
+			c.check {
+			t.Fatalf("'%s' gave check '%c' expected '%c'",
+				c.symbols, check, c.check,
+			)
+		}
+	}
+}
+
+func TestConformance(t *testing.T) {
+
+	codecertest.Run(t, CrockfordCheck)
+}
+
+func TestDecode(t *testing.T) {
+
+	// This is encoded as "01234567DDMQ8RV8CNQ76TBEDC" and the check symbol.
+	input := []byte("\x00\x44\x32\x14\xc7kitchensink")
+	encoded, err := CrockfordCheck.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The letters that look like '0' and '1', lower case and hyphens are all
+	// read as the specification asks.
+	mistaken := strings.NewReplacer("0", "O", "1", "l").Replace(encoded)
+	for _, variant := range []string{
+		strings.ToLower(encoded),
+		encoded[:4] + "-" + encoded[4:8] + "-" + encoded[8:],
+		mistaken,
+		strings.ToLower(mistaken),
+		strings.NewReplacer("1", "I").Replace(encoded),
+	} {
+
+		decoded, err := CrockfordCheck.Decode(variant)
+		if err != nil {
+			t.Fatalf("'%s': %v", variant, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Fatalf("'%s' gave %x expected %x", variant, decoded, input)
+		}
+	}
+
+	for _, c := range []struct {
+		cdc   func(string) ([]byte, error)
+		input string
+		err   error
+	}{
+		{CrockfordCheck.Decode, "", proto.Error_ZERO_LENGTH},
+		{CrockfordCheck.Decode, "--", proto.Error_ZERO_LENGTH},
+		{CrockfordCheck.Decode, "D", proto.Error_CHECK_TOO_SHORT},
+		{CrockfordCheck.Decode, "U" + encoded, proto.Error_INVALID_CHARACTER},
+		{CrockfordCheck.Decode, encoded[:3] + "!" + encoded[4:],
+			proto.Error_INVALID_CHARACTER},
+		{CrockfordCheck.Decode, swapLast(encoded), proto.Error_CHECK_FAILED},
+		{Crockford.Decode, "*", proto.Error_INVALID_CHARACTER},
+		{Crockford.Decode, "CRR", proto.Error_INVALID_LENGTH},
+		{Crockford.Decode, "CS", proto.Error_INVALID_PADDING},
+	} {
+
+		if _, err = c.cdc(c.input); !errors.Is(err, c.err) {
+			t.Fatalf("'%s' gave error %v expected %v", c.input, err, c.err)
+		}
+	}
+}
+
+// swapLast swaps the two symbols before the check symbol.
+func swapLast(s string) string {
+
+	n := len(s)
+
+	return s[:n-3] + s[n-2:n-1] + s[n-3:n-2] + s[n-1:]
+}
@@ -8,7 +8,6 @@ import (
 	"go.uber.org/atomic"
 	"lukechampine.com/blake3"
 	"math/rand"
-	"net"
 	"sync"
 	"testing"
 	"time"
@@ -93,15 +92,12 @@ func TestGRPCCodecConcurrency(t *testing.T) {
 	}
 
 	// Set up a server
-	addr, err := net.ResolveTCPAddr("tcp", defaultAddr)
-	if err != nil {
-		t.Fatal(err)
-	}
+	addr := freeAddr(t)
 	srvr := server.New(addr, 8)
 	stopSrvr := srvr.Start()
 
 	// Create a client
-	cli, err := client.New(defaultAddr, time.Second*5)
+	cli, err := client.New(addr.String(), time.Second*5)
 	if err != nil {
 		t.Fatal(err)
 	}
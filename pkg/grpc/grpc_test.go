@@ -10,17 +10,29 @@ import (
 	"time"
 )
 
-const defaultAddr = "localhost:50051"
+// freeAddr returns an address on the loopback interface with a port that is
+// not in use, so that the server of a test can't clash with one left over from
+// another test that is still shutting down.
+func freeAddr(t *testing.T) (addr *net.TCPAddr) {
 
-func TestGRPC(t *testing.T) {
-	addr, err := net.ResolveTCPAddr("tcp", defaultAddr)
+	lis, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
 	if err != nil {
 		t.Fatal(err)
 	}
+	addr = lis.Addr().(*net.TCPAddr)
+	if err = lis.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestGRPC(t *testing.T) {
+	addr := freeAddr(t)
 	srvr := server.New(addr, 8)
 	stopServer := srvr.Start()
 
-	cli, err := client.New(defaultAddr, 5*time.Second)
+	cli, err := client.New(addr.String(), 5*time.Second)
 	if err != nil {
 		t.Log(err)
 		t.FailNow()
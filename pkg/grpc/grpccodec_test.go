@@ -4,12 +4,14 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"github.com/quanterall/kitchensink/pkg/codecer"
+	"github.com/quanterall/kitchensink/pkg/codecer/codecertest"
 	"github.com/quanterall/kitchensink/pkg/grpc/client"
 	"github.com/quanterall/kitchensink/pkg/grpc/server"
 	"github.com/quanterall/kitchensink/pkg/proto"
+	"github.com/quanterall/kitchensink/pkg/radix"
 	"lukechampine.com/blake3"
 	"math/rand"
-	"net"
 	"testing"
 	"time"
 )
@@ -21,14 +23,11 @@ const (
 
 func TestGRPCCodec(t *testing.T) {
 
-	addr, err := net.ResolveTCPAddr("tcp", defaultAddr)
-	if err != nil {
-		t.Fatal(err)
-	}
+	addr := freeAddr(t)
 	srvr := server.New(addr, 8)
 	stopSrvr := srvr.Start()
 
-	cli, err := client.New(defaultAddr, time.Second*5)
+	cli, err := client.New(addr.String(), time.Second*5)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -214,3 +213,91 @@ func TestGRPCCodec(t *testing.T) {
 	stopCli()
 	stopSrvr()
 }
+
+// grpcCodec makes the gRPC client into a codecer.Codecer, so the remote codec
+// can be held to the same contract as the local ones.
+type grpcCodec struct {
+	enc func(*proto.EncodeRequest) chan *proto.EncodeResponse
+	dec func(*proto.DecodeRequest) chan *proto.DecodeResponse
+}
+
+var _ codecer.Codecer = &grpcCodec{}
+
+func (g *grpcCodec) Encode(input []byte) (output string, err error) {
+
+	res := <-g.enc(&proto.EncodeRequest{Data: input})
+	if e, ok := res.GetEncoded().(*proto.EncodeResponse_Error); ok {
+
+		return "", e.Error
+	}
+
+	return res.GetEncodedString(), nil
+}
+
+func (g *grpcCodec) Decode(input string) (output []byte, err error) {
+
+	res := <-g.dec(&proto.DecodeRequest{EncodedString: input})
+	if e, ok := res.GetDecoded().(*proto.DecodeResponse_Error); ok {
+
+		return nil, e.Error
+	}
+
+	return res.GetData(), nil
+}
+
+func TestGRPCConformance(t *testing.T) {
+
+	addr := freeAddr(t)
+	srvr := server.New(addr, 8)
+	stopSrvr := srvr.Start()
+	defer stopSrvr()
+
+	cli, err := client.New(addr.String(), time.Second*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, dec, stopCli, err := cli.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stopCli()
+
+	codecertest.Run(t, &grpcCodec{enc: enc, dec: dec})
+}
+
+func TestGRPCRadixConformance(t *testing.T) {
+
+	// A port of its own, so this server can't be confused with one left over
+	// from the tests of the based32 service.
+	addr := freeAddr(t)
+	srvr := server.NewWithCodec(addr, 8, radix.Base58)
+	stopSrvr := srvr.Start()
+	defer stopSrvr()
+
+	cli, err := client.New(addr.String(), time.Second*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, dec, stopCli, err := cli.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stopCli()
+
+	g := &grpcCodec{enc: enc, dec: dec}
+	codecertest.Run(t, g)
+
+	// The service gives the same strings as the codec it was created with.
+	input := []byte("kitchensink")
+	expected, err := radix.Base58.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := g.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != expected {
+		t.Fatalf("got '%s' expected '%s'", encoded, expected)
+	}
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"github.com/quanterall/kitchensink/pkg/based32"
+	"github.com/quanterall/kitchensink/pkg/codecer"
 	"github.com/quanterall/kitchensink/pkg/proto"
 	"go.uber.org/atomic"
 	"google.golang.org/grpc"
@@ -22,9 +24,18 @@ type b32 struct {
 	workers     uint32
 }
 
-// New creates a new service handler
+// New creates a new service handler using the based32 codec.
 func New(addr *net.TCPAddr, workers uint32) (b *b32) {
 
+	return NewWithCodec(addr, workers, based32.Codec)
+}
+
+// NewWithCodec creates a new service handler that encodes and decodes with the
+// given codec, such as one of those in the radix package.
+func NewWithCodec(
+	addr *net.TCPAddr, workers uint32, cdc codecer.Codecer,
+) (b *b32) {
+
 	log.Println("creating transcriber service")
 
 	// It would be possible to interlink all of the kill switches in an
@@ -35,7 +46,7 @@ func New(addr *net.TCPAddr, workers uint32) (b *b32) {
 	b = &b32{
 		stop:        stop,
 		svr:         grpc.NewServer(),
-		transcriber: NewWorkerPool(workers, stop),
+		transcriber: NewWorkerPool(workers, stop, cdc),
 		addr:        addr,
 		workers:     workers - 1,
 	}
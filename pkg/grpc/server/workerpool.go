@@ -1,7 +1,7 @@
 package server
 
 import (
-	"github.com/quanterall/kitchensink/pkg/based32"
+	"github.com/quanterall/kitchensink/pkg/codecer"
 	"github.com/quanterall/kitchensink/pkg/proto"
 	"go.uber.org/atomic"
 	"sync"
@@ -12,6 +12,7 @@ import (
 // correctly.
 type transcriber struct {
 	stop                       chan struct{}
+	cdc                        codecer.Codecer
 	encode                     []chan *proto.EncodeRequest
 	decode                     []chan *proto.DecodeRequest
 	encodeRes                  []chan proto.EncodeRes
@@ -23,12 +24,15 @@ type transcriber struct {
 
 // NewWorkerPool initialises the data structure required to run a worker pool.
 // Call Start to to initiate the run, and call the returned stop function to end
-// it.
-func NewWorkerPool(workers uint32, stop chan struct{}) *transcriber {
+// it. The workers encode and decode with the given codec.
+func NewWorkerPool(
+	workers uint32, stop chan struct{}, cdc codecer.Codecer,
+) *transcriber {
 
 	// Initialize a transcriber worker pool
 	t := &transcriber{
 		stop:         stop,
+		cdc:          cdc,
 		encode:       make([]chan *proto.EncodeRequest, workers),
 		decode:       make([]chan *proto.DecodeRequest, workers),
 		encodeRes:    make([]chan proto.EncodeRes, workers),
@@ -62,7 +66,7 @@ out:
 		case msg := <-t.encode[worker]:
 
 			t.encCallCount.Inc()
-			res, err := based32.Codec.Encode(msg.Data)
+			res, err := t.cdc.Encode(msg.Data)
 			t.encodeRes[worker] <- proto.EncodeRes{
 				IdNonce: msg.IdNonce,
 				String:  res,
@@ -73,7 +77,7 @@ out:
 
 			t.decCallCount.Inc()
 
-			bytes, err := based32.Codec.Decode(msg.EncodedString)
+			bytes, err := t.cdc.Decode(msg.EncodedString)
 			t.decodeRes[worker] <- proto.DecodeRes{
 				IdNonce: msg.IdNonce,
 				Bytes:   bytes,
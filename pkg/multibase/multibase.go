@@ -0,0 +1,254 @@
+// Package multibase adds a single character prefix to the strings of codecs,
+// in the style of the multibase format described at
+// https://github.com/multiformats/multibase, so that a string says which codec
+// created it, and can be decoded without knowing that in advance.
+//
+// Only z-base-32 is the same encoding as multibase's, and it has the prefix
+// multibase gives it. The other codecs of the Default set are the formats of
+// this module, with their Human Readable Part and check, which no other
+// implementation of multibase can decode. Giving them the prefixes of the
+// multibase encodings with the same alphabets would make those implementations
+// decode the strings to the wrong bytes without any error, so they have
+// prefixes that multibase leaves unassigned instead.
+package multibase
+
+import (
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/based32"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"github.com/quanterall/kitchensink/pkg/radix"
+	"github.com/quanterall/kitchensink/pkg/zbase32"
+	"sync"
+)
+
+// The prefixes of the codecs in the Default set. All but ZBase32 are not
+// assigned by multibase, as they are not its encodings.
+const (
+	Based32   = 'q'
+	Base58    = 'x'
+	Base36    = 'j'
+	Base64URL = 'y'
+	ZBase32   = 'h'
+)
+
+// Prefixed returns a copy of the codec with the prefix added to the start of
+// its strings, and to its HRP. The decoder of the copy requires the prefix,
+// and decodes the rest of the string with the codec.
+func Prefixed(prefix byte, cdc *codec.Codec) (
+	prefixed *codec.Codec, err error,
+) {
+
+	if err = validPrefix(prefix); err != nil {
+		return
+	}
+
+	p := *cdc
+	prefixed = &p
+	prefixed.HRP = string(prefix) + cdc.HRP
+
+	// The copy only has Encoder and Decoder, which AppendEncode and
+	// AppendDecode of the copy fall back to.
+	prefixed.AppendEncoder = nil
+	prefixed.AppendDecoder = nil
+
+	prefixed.Encoder = func(input []byte) (output string, err error) {
+
+		var encoded []byte
+		if encoded, err = cdc.AppendEncode([]byte{prefix}, input); err != nil {
+			return
+		}
+
+		return string(encoded), nil
+	}
+
+	prefixed.Decoder = func(input string) (output []byte, err error) {
+
+		if len(input) < 1 || input[0] != prefix {
+
+			err = proto.Error_INCORRECT_HUMAN_READABLE_PART
+			return
+		}
+
+		return cdc.AppendDecode(nil, []byte(input[1:]))
+	}
+
+	return
+}
+
+// validPrefix returns an error if the prefix is not a printable ASCII
+// character.
+func validPrefix(prefix byte) (err error) {
+
+	if prefix < 33 || prefix > 126 {
+
+		err = fmt.Errorf("prefix %q is not a printable character", prefix)
+	}
+
+	return
+}
+
+// Multibase is a set of codecs, each with its own prefix. It is safe for
+// concurrent use, though normally all of the codecs are registered at startup
+// and only Encode and Decode are used afterwards.
+type Multibase struct {
+	mx       sync.RWMutex
+	byPrefix map[byte]*codec.Codec
+	byName   map[string]byte
+}
+
+// New creates an empty Multibase.
+func New() (m *Multibase) {
+
+	return &Multibase{
+		byPrefix: make(map[byte]*codec.Codec),
+		byName:   make(map[string]byte),
+	}
+}
+
+// Register adds a codec with the given prefix. An error is returned if the
+// prefix is not a printable ASCII character, or if the prefix or the name of
+// the codec is already registered.
+func (m *Multibase) Register(prefix byte, cdc *codec.Codec) (err error) {
+
+	if err = validPrefix(prefix); err != nil {
+		return
+	}
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if c, ok := m.byPrefix[prefix]; ok {
+
+		err = fmt.Errorf(
+			"prefix '%c' is already registered for codec '%s'", prefix, c.Name,
+		)
+		return
+	}
+
+	if _, ok := m.byName[cdc.Name]; ok {
+
+		err = fmt.Errorf("codec named '%s' is already registered", cdc.Name)
+		return
+	}
+
+	m.byPrefix[prefix] = cdc
+	m.byName[cdc.Name] = prefix
+
+	return
+}
+
+// Get returns the codec registered with the given prefix.
+func (m *Multibase) Get(prefix byte) (cdc *codec.Codec, ok bool) {
+
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	cdc, ok = m.byPrefix[prefix]
+
+	return
+}
+
+// Prefix returns the prefix of the codec registered with the given name.
+func (m *Multibase) Prefix(name string) (prefix byte, ok bool) {
+
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	prefix, ok = m.byName[name]
+
+	return
+}
+
+// Encode encodes the input with the codec registered with the given name, and
+// adds its prefix.
+func (m *Multibase) Encode(name string, input []byte) (
+	output string, err error,
+) {
+
+	m.mx.RLock()
+	prefix, ok := m.byName[name]
+	cdc := m.byPrefix[prefix]
+	m.mx.RUnlock()
+
+	if !ok {
+
+		err = fmt.Errorf("no codec named '%s' is registered", name)
+		return
+	}
+
+	var encoded []byte
+	if encoded, err = cdc.AppendEncode([]byte{prefix}, input); err != nil {
+		return
+	}
+
+	return string(encoded), nil
+}
+
+// Decode finds the codec registered with the prefix of the input and decodes
+// the rest of it, returning the codec that was used along with the output.
+func (m *Multibase) Decode(input string) (
+	cdc *codec.Codec, output []byte, err error,
+) {
+
+	if len(input) < 1 {
+
+		err = proto.Error_ZERO_LENGTH
+		return
+	}
+
+	var ok bool
+	if cdc, ok = m.Get(input[0]); !ok {
+
+		err = proto.Error_INCORRECT_HUMAN_READABLE_PART
+		return
+	}
+
+	output, err = cdc.AppendDecode(nil, []byte(input[1:]))
+
+	return
+}
+
+// Default is the set of codecs used by the package level functions, with the
+// codecs of this module that have a prefix assigned.
+var Default = func() (m *Multibase) {
+
+	m = New()
+	for _, r := range []struct {
+		prefix byte
+		cdc    *codec.Codec
+	}{
+		{Based32, based32.Codec},
+		{Base58, radix.Base58},
+		{Base36, radix.Base36},
+		{Base64URL, radix.Base64URL},
+		{ZBase32, zbase32.Codec},
+	} {
+
+		if err := m.Register(r.prefix, r.cdc); err != nil {
+
+			panic(err)
+		}
+	}
+
+	return
+}()
+
+// Register adds a codec with the given prefix to the Default set.
+func Register(prefix byte, cdc *codec.Codec) (err error) {
+
+	return Default.Register(prefix, cdc)
+}
+
+// Encode encodes the input with the named codec in the Default set.
+func Encode(name string, input []byte) (string, error) {
+
+	return Default.Encode(name, input)
+}
+
+// Decode decodes the input with the codec in the Default set that has its
+// prefix.
+func Decode(input string) (*codec.Codec, []byte, error) {
+
+	return Default.Decode(input)
+}
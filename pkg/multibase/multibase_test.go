@@ -0,0 +1,154 @@
+package multibase
+
+import (
+	"bytes"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/based32"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/codecer/codecertest"
+	"github.com/quanterall/kitchensink/pkg/crockford"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"github.com/quanterall/kitchensink/pkg/radix"
+	"github.com/quanterall/kitchensink/pkg/zbase32"
+	"testing"
+)
+
+func TestDefault(t *testing.T) {
+
+	input := []byte("multibase test input")
+	for _, c := range []struct {
+		prefix byte
+		cdc    *codec.Codec
+	}{
+		{Based32, based32.Codec},
+		{Base58, radix.Base58},
+		{Base36, radix.Base36},
+		{Base64URL, radix.Base64URL},
+		{ZBase32, zbase32.Codec},
+	} {
+
+		encoded, err := Encode(c.cdc.Name, input)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The string is the encoding of the codec after the prefix.
+		expected, err := c.cdc.Encode(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encoded != string(c.prefix)+expected {
+			t.Fatalf("got '%s' expected '%c%s'", encoded, c.prefix, expected)
+		}
+
+		cdc, decoded, err := Decode(encoded)
+		if err != nil {
+			t.Fatalf("'%s': %v", encoded, err)
+		}
+		if cdc != c.cdc {
+			t.Fatalf("'%s' decoded with '%s' expected '%s'",
+				encoded, cdc.Name, c.cdc.Name,
+			)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Fatalf("'%s' gave %x expected %x", encoded, decoded, input)
+		}
+	}
+
+	for _, c := range []struct {
+		input string
+		err   error
+	}{
+		{"", proto.Error_ZERO_LENGTH},
+		{"Q", proto.Error_INCORRECT_HUMAN_READABLE_PART},
+		{"z" + based32.Codec.HRP, proto.Error_INCORRECT_HUMAN_READABLE_PART},
+		{"q", proto.Error_INCORRECT_HUMAN_READABLE_PART},
+		{"qQNTRL", proto.Error_ZERO_LENGTH},
+	} {
+
+		if _, _, err := Decode(c.input); !errors.Is(err, c.err) {
+			t.Fatalf("'%s' gave error %v expected %v", c.input, err, c.err)
+		}
+	}
+
+	// The prefixes multibase gives to base58btc, base36 and base64url are not
+	// used for the formats of this module, which are different encodings.
+	for _, prefix := range []byte{'z', 'k', 'u'} {
+
+		if cdc, ok := Default.Get(prefix); ok {
+			t.Fatalf("prefix '%c' is used for '%s'", prefix, cdc.Name)
+		}
+	}
+
+	if _, err := Encode("Unknown", input); err == nil {
+		t.Fatal("encoded with an unknown codec")
+	}
+}
+
+func TestRegister(t *testing.T) {
+
+	m := New()
+	if err := m.Register('c', crockford.CrockfordCheck); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		prefix byte
+		cdc    *codec.Codec
+	}{
+		{'c', based32.Codec},
+		{'d', crockford.CrockfordCheck},
+		{' ', based32.Codec},
+		{0x80, based32.Codec},
+	} {
+
+		if err := m.Register(c.prefix, c.cdc); err == nil {
+			t.Fatalf("registered '%s' with prefix %q", c.cdc.Name, c.prefix)
+		}
+	}
+
+	if prefix, ok := m.Prefix(crockford.CrockfordCheck.Name); !ok ||
+		prefix != 'c' {
+		t.Fatalf("got prefix %q", prefix)
+	}
+	if cdc, ok := m.Get('c'); !ok || cdc != crockford.CrockfordCheck {
+		t.Fatal("codec not found by its prefix")
+	}
+}
+
+func TestPrefixed(t *testing.T) {
+
+	prefixed, err := Prefixed(Based32, based32.Codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codecertest.Run(t, prefixed)
+
+	if prefixed.HRP != "qQNTRL" {
+		t.Fatalf("got HRP '%s'", prefixed.HRP)
+	}
+
+	// The strings are the same as those of the Default set.
+	input := []byte("multibase test input")
+	encoded, err := prefixed.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := Encode(based32.Codec.Name, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != expected {
+		t.Fatalf("got '%s' expected '%s'", encoded, expected)
+	}
+
+	if _, err = prefixed.Decode(encoded[1:]); !errors.Is(
+		err, proto.Error_INCORRECT_HUMAN_READABLE_PART,
+	) {
+		t.Fatalf("got error %v", err)
+	}
+
+	if _, err = Prefixed(' ', based32.Codec); err == nil {
+		t.Fatal("space was allowed as a prefix")
+	}
+}
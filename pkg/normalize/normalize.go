@@ -0,0 +1,199 @@
+// Package normalize changes characters that are easily mistaken for each
+// other into the one of them a codec uses, so that codes copied by hand, or by
+// optical character recognition, still decode.
+//
+// This is only done where there is no doubt about which character was meant.
+// Each group of confusable characters is looked at separately for each
+// alphabet, and only if exactly one character of the group is a symbol of the
+// alphabet are the others changed into it. So for the based32 alphabet, which
+// has '0' but not 'O' or 'o', an 'O' is read as '0', while '2' and 'Z' are
+// left alone, as both are symbols of the alphabet and either could have been
+// meant.
+//
+// Characters are only ever replaced one for one, so positions in the input,
+// such as the offset of a based32.DecodeError, are the same before and after.
+package normalize
+
+import (
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"strings"
+)
+
+// Confusables are the groups of characters that are used unless others are
+// given. Letters are listed in both cases, as a lower case 'l' and an upper
+// case 'I' look like a '1' as much as each other.
+var Confusables = []string{
+	"0Oo",
+	"1IiLl|",
+	"2Zz",
+	"5Ss",
+	"8B",
+	"UuVv",
+}
+
+// Normalizer changes the confusable characters of an input into the symbols
+// of one alphabet.
+type Normalizer struct {
+
+	// table has the character each character is changed to, which is itself
+	// for those that are not changed.
+	table [256]byte
+
+	// fold is set if the alphabet has letters of only one case, so symbols
+	// are matched in either case, and replacement letters take the case of
+	// the input.
+	fold bool
+}
+
+// New creates a Normalizer for the alphabet from the groups of confusable
+// characters, or from Confusables if none are given. A character can be in
+// only one group.
+func New(alphabet string, groups ...string) (n *Normalizer, err error) {
+
+	if len(groups) < 1 {
+
+		groups = Confusables
+	}
+
+	n = &Normalizer{
+		fold: strings.ToLower(alphabet) == alphabet ||
+			strings.ToUpper(alphabet) == alphabet,
+	}
+	for i := range n.table {
+
+		n.table[i] = byte(i)
+	}
+
+	// inAlphabet returns the character as it is written in the alphabet, if
+	// it is a symbol of it.
+	inAlphabet := func(c byte) (symbol byte, ok bool) {
+
+		if strings.IndexByte(alphabet, c) >= 0 {
+
+			return c, true
+		}
+
+		if n.fold {
+
+			for _, f := range []string{
+				strings.ToLower(string(c)), strings.ToUpper(string(c)),
+			} {
+
+				if strings.IndexByte(alphabet, f[0]) >= 0 {
+
+					return f[0], true
+				}
+			}
+		}
+
+		return 0, false
+	}
+
+	var seen [256]bool
+	for _, group := range groups {
+
+		var target byte
+		var targets int
+		for i := 0; i < len(group); i++ {
+
+			if seen[group[i]] {
+
+				err = fmt.Errorf(
+					"character '%c' is in more than one group", group[i],
+				)
+				return nil, err
+			}
+			seen[group[i]] = true
+
+			if symbol, ok := inAlphabet(group[i]); ok && symbol != target {
+
+				target = symbol
+				targets++
+			}
+		}
+
+		// With no symbol of the alphabet in the group there is nothing to
+		// change to, and with more than one it can't be known which was meant.
+		if targets != 1 {
+			continue
+		}
+
+		for i := 0; i < len(group); i++ {
+
+			if _, ok := inAlphabet(group[i]); !ok {
+
+				n.table[group[i]] = target
+			}
+		}
+	}
+
+	return
+}
+
+// Normalize returns the input with the confusable characters changed into the
+// symbols of the alphabet.
+//
+// If the alphabet has only one case, the replacement letters are written in
+// upper case if the input has upper case letters and no lower case ones, so
+// that a code written all in upper case stays that way.
+func (n *Normalizer) Normalize(input string) string {
+
+	upper := false
+	if n.fold {
+
+		upper = strings.ToLower(input) != input &&
+			strings.ToUpper(input) == input
+	}
+
+	output := []byte(input)
+	for i, c := range output {
+
+		if r := n.table[c]; r != c {
+
+			if upper && r >= 'a' && r <= 'z' {
+
+				r = r - 'a' + 'A'
+			} else if n.fold && !upper && r >= 'A' && r <= 'Z' {
+
+				r = r - 'A' + 'a'
+			}
+			output[i] = r
+		}
+	}
+
+	return string(output)
+}
+
+// Wrap returns a copy of the codec that normalizes its input, after the Human
+// Readable Part, before decoding it. The groups are as for New.
+//
+// This suits codecs whose strings are the HRP followed by symbols of their
+// charset and separators, such as those of the based32 and radix packages, but
+// not those with other characters after the HRP, such as the '1' that ends the
+// HRP of the bech32 package. Those can use a Normalizer directly.
+func Wrap(cdc *codec.Codec, groups ...string) (wrapped *codec.Codec, err error) {
+
+	var n *Normalizer
+	if n, err = New(cdc.Charset, groups...); err != nil {
+		return
+	}
+
+	w := *cdc
+	wrapped = &w
+
+	// AppendDecode calls whichever of the decoders the codec has, and the copy
+	// only has Decoder, which AppendDecode of the copy falls back to.
+	wrapped.AppendDecoder = nil
+	wrapped.Decoder = func(input string) (output []byte, err error) {
+
+		if len(input) > len(cdc.HRP) {
+
+			input = input[:len(cdc.HRP)] + n.Normalize(input[len(cdc.HRP):])
+		}
+
+		return cdc.AppendDecode(nil, []byte(input))
+	}
+
+	return
+}
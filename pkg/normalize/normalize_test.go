@@ -0,0 +1,90 @@
+package normalize
+
+import (
+	"bytes"
+	"github.com/quanterall/kitchensink/pkg/based32"
+	"github.com/quanterall/kitchensink/pkg/codecer/codecertest"
+	"github.com/quanterall/kitchensink/pkg/radix"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+
+	for _, c := range []struct {
+		alphabet, input, expected string
+	}{
+		// The based32 alphabet has '0', 'l' and '8', but both of '2' and 'z',
+		// '5' and 's', and 'u' and 'v'.
+		{based32.Codec.Charset, "O0oIi1|lL8Bb2Zz5SsUuVv",
+			"000lllllL88b2Zz5SsUuVv"},
+		{based32.Codec.Charset, "QNTRLO1B", "QNTRL0L8"},
+		// Base58 has both cases, so they are not folded, and 'o' is the only
+		// one of its group, while '1', 'i' and 'L' are all symbols.
+		{radix.Base58Alphabet, "O0o1IiLl|", "ooo1IiLl|"},
+		// Base36 has all of the digits and letters.
+		{radix.Base36Alphabet, "O0oIi1|lL", "O0oIi1|lL"},
+	} {
+
+		n, err := New(c.alphabet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if normalized := n.Normalize(c.input); normalized != c.expected {
+			t.Fatalf("'%s' normalized to '%s' expected '%s'",
+				c.input, normalized, c.expected,
+			)
+		}
+	}
+
+	if _, err := New(based32.Codec.Charset, "0O", "oO"); err == nil {
+		t.Fatal("'O' was allowed in two groups")
+	}
+}
+
+func TestWrap(t *testing.T) {
+
+	wrapped, err := Wrap(based32.Codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codecertest.Run(t, wrapped)
+
+	input := []byte("kitchensink")
+	encoded, err := based32.Codec.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The HRP is left as it is, and upper case codes stay upper case.
+	copied := strings.NewReplacer("0", "O", "l", "1", "8", "B").Replace(encoded)
+	if copied == encoded {
+		t.Fatalf("'%s' has none of the characters to replace", encoded)
+	}
+	for _, variant := range []string{
+		copied,
+		strings.ToUpper(copied),
+		strings.ToLower(copied[:5]) + copied[5:],
+	} {
+
+		if _, err = based32.Codec.Decode(variant); err == nil {
+			t.Fatalf("'%s' decoded without normalizing", variant)
+		}
+
+		decoded, err := wrapped.Decode(variant)
+		if err != nil {
+			t.Fatalf("'%s': %v", variant, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Fatalf("'%s' gave %x expected %x", variant, decoded, input)
+		}
+
+		decoded, err = wrapped.AppendDecode(nil, []byte(variant))
+		if err != nil {
+			t.Fatalf("'%s': %v", variant, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Fatalf("'%s' gave %x expected %x", variant, decoded, input)
+		}
+	}
+}
@@ -28,16 +28,42 @@ const (
 	Error_NIL_SLICE                     Error = 2
 	Error_CHECK_TOO_SHORT               Error = 3
 	Error_INCORRECT_HUMAN_READABLE_PART Error = 4
+	Error_INVALID_CHARACTER             Error = 5
+	Error_MIXED_CASE                    Error = 6
+	Error_INVALID_LENGTH                Error = 7
+	Error_MISSING_SEPARATOR             Error = 8
+	Error_INVALID_PADDING               Error = 9
+	Error_UNSUPPORTED_VERSION           Error = 10
+	Error_UNKNOWN_WORD                  Error = 11
+	Error_AMBIGUOUS_WORD                Error = 12
+	Error_INVALID_CHECK_LENGTH          Error = 13
+	Error_NON_CANONICAL                 Error = 14
+	Error_INVALID_COMPRESSION           Error = 15
+	Error_DECOMPRESSED_TOO_LARGE        Error = 16
+	Error_UNKNOWN                       Error = 17
 )
 
 // Enum value maps for Error.
 var (
 	Error_name = map[int32]string{
-		0: "ZERO_LENGTH",
-		1: "CHECK_FAILED",
-		2: "NIL_SLICE",
-		3: "CHECK_TOO_SHORT",
-		4: "INCORRECT_HUMAN_READABLE_PART",
+		0:  "ZERO_LENGTH",
+		1:  "CHECK_FAILED",
+		2:  "NIL_SLICE",
+		3:  "CHECK_TOO_SHORT",
+		4:  "INCORRECT_HUMAN_READABLE_PART",
+		5:  "INVALID_CHARACTER",
+		6:  "MIXED_CASE",
+		7:  "INVALID_LENGTH",
+		8:  "MISSING_SEPARATOR",
+		9:  "INVALID_PADDING",
+		10: "UNSUPPORTED_VERSION",
+		11: "UNKNOWN_WORD",
+		12: "AMBIGUOUS_WORD",
+		13: "INVALID_CHECK_LENGTH",
+		14: "NON_CANONICAL",
+		15: "INVALID_COMPRESSION",
+		16: "DECOMPRESSED_TOO_LARGE",
+		17: "UNKNOWN",
 	}
 	Error_value = map[string]int32{
 		"ZERO_LENGTH":                   0,
@@ -45,6 +71,19 @@ var (
 		"NIL_SLICE":                     2,
 		"CHECK_TOO_SHORT":               3,
 		"INCORRECT_HUMAN_READABLE_PART": 4,
+		"INVALID_CHARACTER":             5,
+		"MIXED_CASE":                    6,
+		"INVALID_LENGTH":                7,
+		"MISSING_SEPARATOR":             8,
+		"INVALID_PADDING":               9,
+		"UNSUPPORTED_VERSION":           10,
+		"UNKNOWN_WORD":                  11,
+		"AMBIGUOUS_WORD":                12,
+		"INVALID_CHECK_LENGTH":          13,
+		"NON_CANONICAL":                 14,
+		"INVALID_COMPRESSION":           15,
+		"DECOMPRESSED_TOO_LARGE":        16,
+		"UNKNOWN":                       17,
 	}
 )
 
@@ -390,25 +429,43 @@ var file_based32_proto_rawDesc = []byte{
 	0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e,
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x48, 0x00, 0x52, 0x05, 0x45,
 	0x72, 0x72, 0x6f, 0x72, 0x42, 0x09, 0x0a, 0x07, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x64, 0x2a,
-	0x71, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x0f, 0x0a, 0x0b, 0x5a, 0x45, 0x52, 0x4f,
-	0x5f, 0x4c, 0x45, 0x4e, 0x47, 0x54, 0x48, 0x10, 0x00, 0x12, 0x10, 0x0a, 0x0c, 0x43, 0x48, 0x45,
-	0x43, 0x4b, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x4e,
-	0x49, 0x4c, 0x5f, 0x53, 0x4c, 0x49, 0x43, 0x45, 0x10, 0x02, 0x12, 0x13, 0x0a, 0x0f, 0x43, 0x48,
-	0x45, 0x43, 0x4b, 0x5f, 0x54, 0x4f, 0x4f, 0x5f, 0x53, 0x48, 0x4f, 0x52, 0x54, 0x10, 0x03, 0x12,
-	0x21, 0x0a, 0x1d, 0x49, 0x4e, 0x43, 0x4f, 0x52, 0x52, 0x45, 0x43, 0x54, 0x5f, 0x48, 0x55, 0x4d,
-	0x41, 0x4e, 0x5f, 0x52, 0x45, 0x41, 0x44, 0x41, 0x42, 0x4c, 0x45, 0x5f, 0x50, 0x41, 0x52, 0x54,
-	0x10, 0x04, 0x32, 0x83, 0x01, 0x0a, 0x0b, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x62,
-	0x65, 0x72, 0x12, 0x39, 0x0a, 0x06, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6e, 0x63, 0x6f, 0x64,
-	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x12, 0x39, 0x0a,
-	0x06, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
-	0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68,
-	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x65, 0x72, 0x61, 0x6c,
-	0x6c, 0x2f, 0x6b, 0x69, 0x74, 0x63, 0x68, 0x65, 0x6e, 0x73, 0x69, 0x6e, 0x6b, 0x2f, 0x70, 0x6b,
-	0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x86, 0x03, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x0f, 0x0a, 0x0b, 0x5a, 0x45, 0x52,
+	0x4f, 0x5f, 0x4c, 0x45, 0x4e, 0x47, 0x54, 0x48, 0x10, 0x00, 0x12, 0x10, 0x0a, 0x0c, 0x43, 0x48,
+	0x45, 0x43, 0x4b, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09,
+	0x4e, 0x49, 0x4c, 0x5f, 0x53, 0x4c, 0x49, 0x43, 0x45, 0x10, 0x02, 0x12, 0x13, 0x0a, 0x0f, 0x43,
+	0x48, 0x45, 0x43, 0x4b, 0x5f, 0x54, 0x4f, 0x4f, 0x5f, 0x53, 0x48, 0x4f, 0x52, 0x54, 0x10, 0x03,
+	0x12, 0x21, 0x0a, 0x1d, 0x49, 0x4e, 0x43, 0x4f, 0x52, 0x52, 0x45, 0x43, 0x54, 0x5f, 0x48, 0x55,
+	0x4d, 0x41, 0x4e, 0x5f, 0x52, 0x45, 0x41, 0x44, 0x41, 0x42, 0x4c, 0x45, 0x5f, 0x50, 0x41, 0x52,
+	0x54, 0x10, 0x04, 0x12, 0x15, 0x0a, 0x11, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x43,
+	0x48, 0x41, 0x52, 0x41, 0x43, 0x54, 0x45, 0x52, 0x10, 0x05, 0x12, 0x0e, 0x0a, 0x0a, 0x4d, 0x49,
+	0x58, 0x45, 0x44, 0x5f, 0x43, 0x41, 0x53, 0x45, 0x10, 0x06, 0x12, 0x12, 0x0a, 0x0e, 0x49, 0x4e,
+	0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x4c, 0x45, 0x4e, 0x47, 0x54, 0x48, 0x10, 0x07, 0x12, 0x15,
+	0x0a, 0x11, 0x4d, 0x49, 0x53, 0x53, 0x49, 0x4e, 0x47, 0x5f, 0x53, 0x45, 0x50, 0x41, 0x52, 0x41,
+	0x54, 0x4f, 0x52, 0x10, 0x08, 0x12, 0x13, 0x0a, 0x0f, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44,
+	0x5f, 0x50, 0x41, 0x44, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x09, 0x12, 0x17, 0x0a, 0x13, 0x55, 0x4e,
+	0x53, 0x55, 0x50, 0x50, 0x4f, 0x52, 0x54, 0x45, 0x44, 0x5f, 0x56, 0x45, 0x52, 0x53, 0x49, 0x4f,
+	0x4e, 0x10, 0x0a, 0x12, 0x10, 0x0a, 0x0c, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x5f, 0x57,
+	0x4f, 0x52, 0x44, 0x10, 0x0b, 0x12, 0x12, 0x0a, 0x0e, 0x41, 0x4d, 0x42, 0x49, 0x47, 0x55, 0x4f,
+	0x55, 0x53, 0x5f, 0x57, 0x4f, 0x52, 0x44, 0x10, 0x0c, 0x12, 0x18, 0x0a, 0x14, 0x49, 0x4e, 0x56,
+	0x41, 0x4c, 0x49, 0x44, 0x5f, 0x43, 0x48, 0x45, 0x43, 0x4b, 0x5f, 0x4c, 0x45, 0x4e, 0x47, 0x54,
+	0x48, 0x10, 0x0d, 0x12, 0x11, 0x0a, 0x0d, 0x4e, 0x4f, 0x4e, 0x5f, 0x43, 0x41, 0x4e, 0x4f, 0x4e,
+	0x49, 0x43, 0x41, 0x4c, 0x10, 0x0e, 0x12, 0x17, 0x0a, 0x13, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49,
+	0x44, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x52, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x10, 0x0f, 0x12,
+	0x1a, 0x0a, 0x16, 0x44, 0x45, 0x43, 0x4f, 0x4d, 0x50, 0x52, 0x45, 0x53, 0x53, 0x45, 0x44, 0x5f,
+	0x54, 0x4f, 0x4f, 0x5f, 0x4c, 0x41, 0x52, 0x47, 0x45, 0x10, 0x10, 0x12, 0x0b, 0x0a, 0x07, 0x55,
+	0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x11, 0x32, 0x83, 0x01, 0x0a, 0x0b, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x12, 0x39, 0x0a, 0x06, 0x45, 0x6e, 0x63, 0x6f,
+	0x64, 0x65, 0x12, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6e, 0x63, 0x6f, 0x64,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28,
+	0x01, 0x30, 0x01, 0x12, 0x39, 0x0a, 0x06, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x65, 0x63, 0x6f,
+	0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x2d,
+	0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x71, 0x75, 0x61,
+	0x6e, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x6c, 0x2f, 0x6b, 0x69, 0x74, 0x63, 0x68, 0x65, 0x6e, 0x73,
+	0x69, 0x6e, 0x6b, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
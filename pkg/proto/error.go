@@ -5,6 +5,10 @@
 // generated code that make programming the protocol simpler.
 package proto
 
+import (
+	"errors"
+)
+
 // The following line generates the protocol, it assumes that `protoc` is in the
 // path. This directive is run when `go generate` is run in the current package,
 // or if a wildcard was used ( go generate ./... ).
@@ -27,6 +31,28 @@ func (x Error) Error() string {
 	return Error_name[int32(x)]
 }
 
+// CodeOf returns the Error code of an error, which may be an Error itself, or
+// wrap one, or be a richer error type that can be converted to one by
+// errors.As, such as the DecodeError of the based32 package.
+//
+// Errors that are none of these are looked up by their text, as the error
+// codes were before there were richer errors. Any other error, such as a
+// failure to read or write, is not one of the codes and gives UNKNOWN, so
+// that it isn't mistaken for one of them.
+func CodeOf(err error) (code Error) {
+
+	if errors.As(err, &code) {
+		return
+	}
+
+	if value, ok := Error_value[err.Error()]; ok {
+
+		return Error(value)
+	}
+
+	return Error_UNKNOWN
+}
+
 // EncodeRes makes a more convenient return type for the results
 type EncodeRes struct {
 	IdNonce uint64
@@ -58,7 +84,7 @@ func CreateEncodeResponse(res EncodeRes) (response *EncodeResponse) {
 
 	if res.Error != nil {
 		response.Encoded = &EncodeResponse_Error{
-			Error(Error_value[res.Error.Error()]),
+			CodeOf(res.Error),
 		}
 	} else {
 		response.Encoded =
@@ -79,7 +105,7 @@ func CreateDecodeResponse(res DecodeRes) (response *DecodeResponse) {
 	// Return an error if there is an error, otherwise return the response data.
 	if res.Error != nil {
 		response.Decoded = &DecodeResponse_Error{
-			Error(Error_value[res.Error.Error()]),
+			CodeOf(res.Error),
 		}
 	} else {
 		response.Decoded = &DecodeResponse_Data{Data: res.Bytes}
@@ -0,0 +1,173 @@
+package qr
+
+// eccPerBlock is the number of error correction codewords in each block, by
+// level and version. The first entry of each row is unused as versions start
+// at 1.
+var eccPerBlock = [4][41]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30,
+		28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30,
+		30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28,
+		26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28,
+		28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28,
+		26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30,
+		30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28,
+		26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30,
+		30, 30, 30, 30},
+}
+
+// numBlocks is the number of error correction blocks the codewords are split
+// into, by level and version.
+var numBlocks = [4][41]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10,
+		12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17,
+		17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47,
+		49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23,
+		23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65,
+		68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25,
+		34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77,
+		81},
+}
+
+// numRawDataModules returns the number of modules of a version that are left
+// for codewords once the function patterns have been drawn.
+func numRawDataModules(version int) (n int) {
+
+	n = (16*version+128)*version + 64
+	if version >= 2 {
+
+		numAlign := version/7 + 2
+		n -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+
+			// The two version information blocks.
+			n -= 36
+		}
+	}
+
+	return
+}
+
+// numDataCodewords returns the number of codewords of data a version holds at
+// a level, which is what is left after error correction.
+func numDataCodewords(version int, level Level) int {
+
+	return numRawDataModules(version)/8 -
+		eccPerBlock[level][version]*numBlocks[level][version]
+}
+
+// addECC splits the data into blocks, adds the error correction codewords to
+// each, and interleaves the blocks, giving the codewords in the order they are
+// placed in the code.
+func addECC(data []byte, version int, level Level) (codewords []byte) {
+
+	blocks := numBlocks[level][version]
+	eccLen := eccPerBlock[level][version]
+	raw := numRawDataModules(version) / 8
+
+	// When the codewords don't divide evenly, the later blocks have one more
+	// data codeword than the earlier, short blocks.
+	numShort := blocks - raw%blocks
+	shortLen := raw / blocks
+	divisor := rsDivisor(eccLen)
+
+	split := make([][]byte, blocks)
+	for i, k := 0, 0; i < blocks; i++ {
+
+		dataLen := shortLen - eccLen
+		if i >= numShort {
+
+			dataLen++
+		}
+
+		block := append([]byte{}, data[k:k+dataLen]...)
+		k += dataLen
+		ecc := rsRemainder(block, divisor)
+
+		// A placeholder keeps the error correction codewords of all blocks at
+		// the same index for interleaving, and is skipped.
+		if i < numShort {
+
+			block = append(block, 0)
+		}
+		split[i] = append(block, ecc...)
+	}
+
+	for i := range split[0] {
+
+		for j, block := range split {
+
+			if i != shortLen-eccLen || j >= numShort {
+
+				codewords = append(codewords, block[i])
+			}
+		}
+	}
+
+	return
+}
+
+// gfMultiply multiplies two elements of the Galois field GF(2^8) with the
+// modulus x^8 + x^4 + x^3 + x^2 + 1 used by QR codes.
+func gfMultiply(x, y byte) (z byte) {
+
+	for i := 7; i >= 0; i-- {
+
+		z = z<<1 ^ z>>7*0x1d
+		z ^= (y >> i & 1) * x
+	}
+
+	return
+}
+
+// rsDivisor returns the Reed-Solomon generator polynomial of the given degree,
+// with the coefficients from the highest power down, leaving out the leading
+// coefficient, which is always 1.
+func rsDivisor(degree int) (divisor []byte) {
+
+	divisor = make([]byte, degree)
+	divisor[degree-1] = 1
+
+	// Multiply together the factors (x - r^i) for i from 0 to degree-1, where
+	// r is the generator 0x02 of the field.
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+
+		for j := range divisor {
+
+			divisor[j] = gfMultiply(divisor[j], root)
+			if j+1 < len(divisor) {
+
+				divisor[j] ^= divisor[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+
+	return
+}
+
+// rsRemainder returns the Reed-Solomon error correction codewords of the data,
+// which is the remainder of dividing it by the divisor.
+func rsRemainder(data, divisor []byte) (remainder []byte) {
+
+	remainder = make([]byte, len(divisor))
+	for _, b := range data {
+
+		factor := b ^ remainder[0]
+		copy(remainder, remainder[1:])
+		remainder[len(remainder)-1] = 0
+
+		for i, d := range divisor {
+
+			remainder[i] ^= gfMultiply(d, factor)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,428 @@
+package qr
+
+// draw lays out the code: the function patterns that let a scanner find and
+// read it, the codewords, and the best of the mask patterns.
+func (c *Code) draw(codewords []byte) {
+
+	c.modules = make([][]bool, c.Size)
+	c.isFunction = make([][]bool, c.Size)
+	for i := range c.modules {
+
+		c.modules[i] = make([]bool, c.Size)
+		c.isFunction[i] = make([]bool, c.Size)
+	}
+
+	c.drawFunctionPatterns()
+	c.drawCodewords(codewords)
+
+	// Each mask is tried in turn, and the one with the lowest penalty, which
+	// is the one least likely to confuse a scanner, is kept. Applying a mask
+	// twice undoes it.
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		if penalty := c.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+
+			best, bestPenalty = mask, penalty
+		}
+		c.applyMask(mask)
+	}
+
+	c.Mask = best
+	c.applyMask(best)
+	c.drawFormatBits(best)
+}
+
+// setFunction sets a module of a function pattern, which is never masked.
+func (c *Code) setFunction(x, y int, dark bool) {
+
+	c.modules[y][x] = dark
+	c.isFunction[y][x] = true
+}
+
+// drawFunctionPatterns draws the timing, finder and alignment patterns, and
+// reserves the space for the format and version information.
+func (c *Code) drawFunctionPatterns() {
+
+	// The timing patterns are alternating modules along row and column 6.
+	for i := 0; i < c.Size; i++ {
+
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	// The finder patterns in three of the corners, which overwrite the ends
+	// of the timing patterns.
+	c.drawFinderPattern(3, 3)
+	c.drawFinderPattern(c.Size-4, 3)
+	c.drawFinderPattern(3, c.Size-4)
+
+	// The alignment patterns, except where they would overlap the finder
+	// patterns.
+	positions := c.alignmentPositions()
+	last := len(positions) - 1
+	for i, x := range positions {
+
+		for j, y := range positions {
+
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			c.drawAlignmentPattern(x, y)
+		}
+	}
+
+	// The format bits are drawn for real once the mask is chosen.
+	c.drawFormatBits(0)
+	c.drawVersion()
+}
+
+// abs returns the absolute value of an int.
+func abs(i int) int {
+
+	if i < 0 {
+
+		return -i
+	}
+
+	return i
+}
+
+// max returns the larger of two ints.
+func max(a, b int) int {
+
+	if a > b {
+
+		return a
+	}
+
+	return b
+}
+
+// drawFinderPattern draws a finder pattern, with its separator, centred on the
+// module at x, y.
+func (c *Code) drawFinderPattern(x, y int) {
+
+	for dy := -4; dy <= 4; dy++ {
+
+		for dx := -4; dx <= 4; dx++ {
+
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= c.Size || yy < 0 || yy >= c.Size {
+				continue
+			}
+
+			dist := max(abs(dx), abs(dy))
+			c.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignmentPattern draws an alignment pattern centred on x, y.
+func (c *Code) drawAlignmentPattern(x, y int) {
+
+	for dy := -2; dy <= 2; dy++ {
+
+		for dx := -2; dx <= 2; dx++ {
+
+			c.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// alignmentPositions returns the rows and columns that the centres of the
+// alignment patterns are on, which are spaced evenly between column 6 and the
+// far edge.
+func (c *Code) alignmentPositions() (positions []int) {
+
+	if c.Version == 1 {
+		return
+	}
+
+	numAlign := c.Version/7 + 2
+	step := (c.Version*8 + numAlign*3 + 5) / (numAlign*4 - 4) * 2
+
+	positions = make([]int, numAlign)
+	positions[0] = 6
+	for i, pos := numAlign-1, c.Size-7; i >= 1; i, pos = i-1, pos-step {
+
+		positions[i] = pos
+	}
+
+	return
+}
+
+// bit returns true if bit i of x is set.
+func bit(x, i int) bool { return x>>i&1 != 0 }
+
+// drawFormatBits draws the two copies of the format information, which is the
+// error correction level and mask, protected by a BCH code.
+func (c *Code) drawFormatBits(mask int) {
+
+	data := c.Level.formatBits()<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+
+		rem = rem<<1 ^ rem>>9*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	// The first copy is around the top left finder pattern.
+	for i := 0; i <= 5; i++ {
+
+		c.setFunction(8, i, bit(bits, i))
+	}
+	c.setFunction(8, 7, bit(bits, 6))
+	c.setFunction(8, 8, bit(bits, 7))
+	c.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+
+		c.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	// The second copy is split between the other two finder patterns.
+	for i := 0; i < 8; i++ {
+
+		c.setFunction(c.Size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+
+		c.setFunction(8, c.Size-15+i, bit(bits, i))
+	}
+
+	// This module is always dark.
+	c.setFunction(8, c.Size-8, true)
+}
+
+// drawVersion draws the two copies of the version information, which codes
+// of version 7 and up have, protected by a BCH code.
+func (c *Code) drawVersion() {
+
+	if c.Version < 7 {
+		return
+	}
+
+	rem := c.Version
+	for i := 0; i < 12; i++ {
+
+		rem = rem<<1 ^ rem>>11*0x1f25
+	}
+	bits := c.Version<<12 | rem
+
+	for i := 0; i < 18; i++ {
+
+		a, b := c.Size-11+i%3, i/3
+		c.setFunction(a, b, bit(bits, i))
+		c.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords places the codewords in the modules that are not part of the
+// function patterns, in pairs of columns from right to left, going up and down
+// in turn.
+func (c *Code) drawCodewords(codewords []byte) {
+
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+
+		// Column 6 is the vertical timing pattern, so it is skipped.
+		if right == 6 {
+
+			right = 5
+		}
+
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+
+			y := vert
+			if upward {
+
+				y = c.Size - 1 - vert
+			}
+
+			for j := 0; j < 2; j++ {
+
+				x := right - j
+				if c.isFunction[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+
+				c.modules[y][x] = codewords[i/8]>>(7-i%8)&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// applyMask inverts the modules that are not part of the function patterns
+// where the mask pattern is true.
+func (c *Code) applyMask(mask int) {
+
+	for y := 0; y < c.Size; y++ {
+
+		for x := 0; x < c.Size; x++ {
+
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+
+			if invert && !c.isFunction[y][x] {
+
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// The weights of the penalty rules.
+const (
+	penaltyN1 = 3
+	penaltyN2 = 3
+	penaltyN3 = 40
+	penaltyN4 = 10
+)
+
+// penalty scores the code by the rules of the standard for features that make
+// it harder to scan: long runs of the same colour, 2 by 2 blocks of the same
+// colour, patterns that look like finder patterns, and an imbalance of dark
+// and light.
+func (c *Code) penalty() (result int) {
+
+	// Runs and finder-like patterns in the rows and then the columns.
+	for _, rows := range []bool{true, false} {
+
+		for i := 0; i < c.Size; i++ {
+
+			runColor, run := false, 0
+			var history [7]int
+			for j := 0; j < c.Size; j++ {
+
+				module := c.modules[i][j]
+				if !rows {
+
+					module = c.modules[j][i]
+				}
+
+				if module == runColor {
+
+					run++
+					if run == 5 {
+
+						result += penaltyN1
+					} else if run > 5 {
+
+						result++
+					}
+					continue
+				}
+
+				c.addHistory(run, &history)
+				if !runColor {
+
+					result += countFinderLike(&history) * penaltyN3
+				}
+				runColor, run = module, 1
+			}
+			result += c.terminateHistory(runColor, run, &history) * penaltyN3
+		}
+	}
+
+	// Blocks of 2 by 2 modules of the same colour.
+	for y := 0; y < c.Size-1; y++ {
+
+		for x := 0; x < c.Size-1; x++ {
+
+			color := c.modules[y][x]
+			if color == c.modules[y][x+1] && color == c.modules[y+1][x] &&
+				color == c.modules[y+1][x+1] {
+
+				result += penaltyN2
+			}
+		}
+	}
+
+	// The further the proportion of dark modules from half, the higher the
+	// penalty, in steps of 5%.
+	dark := 0
+	for _, row := range c.modules {
+
+		for _, module := range row {
+
+			if module {
+
+				dark++
+			}
+		}
+	}
+	total := c.Size * c.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	result += k * penaltyN4
+
+	return
+}
+
+// addHistory records the length of a run of modules that has ended. The light
+// area outside the code counts as part of the first run.
+func (c *Code) addHistory(run int, history *[7]int) {
+
+	if history[0] == 0 {
+
+		run += c.Size
+	}
+	copy(history[1:], history[:6])
+	history[0] = run
+}
+
+// countFinderLike returns how many patterns that look like a finder pattern,
+// which is dark, light, dark, light, dark in the ratio 1:1:3:1:1 with light on
+// one side 4 times as long, end at the latest run.
+func countFinderLike(history *[7]int) (count int) {
+
+	n := history[1]
+	core := n > 0 && history[2] == n && history[3] == n*3 && history[4] == n &&
+		history[5] == n
+	if core && history[0] >= n*4 && history[6] >= n {
+
+		count++
+	}
+	if core && history[6] >= n*4 && history[0] >= n {
+
+		count++
+	}
+
+	return
+}
+
+// terminateHistory ends the last run of a row or column, adding the light area
+// outside the code, and counts the finder-like patterns.
+func (c *Code) terminateHistory(runColor bool, run int, history *[7]int,
+) int {
+
+	if runColor {
+
+		c.addHistory(run, history)
+		run = 0
+	}
+	run += c.Size
+	c.addHistory(run, history)
+
+	return countFinderLike(history)
+}
@@ -0,0 +1,326 @@
+// Package qr generates QR codes, to show encoded strings on screens and labels
+// where they can be scanned instead of typed, entirely in Go without any
+// dependencies outside the standard library.
+//
+// QR codes can hold text in several modes. The alphanumeric mode holds the
+// digits, upper case letters and a few symbols in 5.5 bits per character,
+// rather than the 8 bits of the byte mode, and Encode picks it whenever the
+// text allows, so codes encoded in upper case, such as by a based32 codec with
+// the WithUppercase option, make noticeably smaller and easier to scan QR
+// codes:
+//
+//	s, _ := based32.Codec.Encode(data)
+//	code, err := qr.Encode(strings.ToUpper(s), qr.Medium)
+//
+// The construction follows ISO/IEC 18004, and the implementation is organised
+// in the same way as Project Nayuki's QR Code generator library.
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Level is the error correction level of a QR code, which is how much of the
+// code can be damaged or obscured while it can still be read, at the cost of
+// a larger code.
+type Level int
+
+const (
+
+	// Low recovers about 7% of the code.
+	Low Level = iota
+
+	// Medium recovers about 15% of the code.
+	Medium
+
+	// Quartile recovers about 25% of the code.
+	Quartile
+
+	// High recovers about 30% of the code.
+	High
+)
+
+// String returns the letter used for the level in the standard.
+func (l Level) String() string {
+
+	switch l {
+	case Low:
+		return "L"
+	case Medium:
+		return "M"
+	case Quartile:
+		return "Q"
+	case High:
+		return "H"
+	default:
+		return "unknown"
+	}
+}
+
+// formatBits returns the bits that identify the level in the format
+// information of a code, which are not in the same order as the levels.
+func (l Level) formatBits() int {
+
+	return [...]int{1, 0, 3, 2}[l]
+}
+
+// Mode is the way the text is stored in a QR code.
+type Mode int
+
+const (
+
+	// Numeric holds only the digits 0 to 9.
+	Numeric Mode = iota
+
+	// Alphanumeric holds the digits, the upper case letters, and the
+	// characters in " $%*+-./:".
+	Alphanumeric
+
+	// Byte holds any bytes.
+	Byte
+)
+
+// String returns the name of the mode.
+func (m Mode) String() string {
+
+	switch m {
+	case Numeric:
+		return "Numeric"
+	case Alphanumeric:
+		return "Alphanumeric"
+	case Byte:
+		return "Byte"
+	default:
+		return "unknown"
+	}
+}
+
+// alphanumeric is the character set of the Alphanumeric mode, in order of
+// their values.
+const alphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// indicator returns the 4 bit mode indicator that starts the data.
+func (m Mode) indicator() int {
+
+	return [...]int{1, 2, 4}[m]
+}
+
+// countBits returns the number of bits of the character count in a version.
+func (m Mode) countBits(version int) int {
+
+	i := 0
+	switch {
+	case version >= 27:
+		i = 2
+	case version >= 10:
+		i = 1
+	}
+
+	return [...][3]int{{10, 12, 14}, {9, 11, 13}, {8, 16, 16}}[m][i]
+}
+
+const (
+
+	// MinVersion and MaxVersion are the range of sizes of QR codes. A code of
+	// version v is 17+4v modules square.
+	MinVersion = 1
+	MaxVersion = 40
+)
+
+// ErrTooLong is returned when the text can't fit in the largest QR code at the
+// chosen error correction level.
+var ErrTooLong = errors.New("text too long for a QR code")
+
+// Code is a QR code.
+type Code struct {
+
+	// Version is the size of the code, from MinVersion to MaxVersion.
+	Version int
+
+	// Level is the error correction level.
+	Level Level
+
+	// Mode is the mode the text is stored in.
+	Mode Mode
+
+	// Mask is the mask pattern chosen to make the code easy to scan.
+	Mask int
+
+	// Size is the width and height of the code in modules, not including the
+	// quiet zone.
+	Size int
+
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// Dark returns true if the module at column x and row y is dark. Modules
+// outside the code, such as those of the quiet zone, are light.
+func (c *Code) Dark(x, y int) bool {
+
+	return x >= 0 && x < c.Size && y >= 0 && y < c.Size && c.modules[y][x]
+}
+
+// modeOf returns the most compact mode that can hold the text.
+func modeOf(text string) Mode {
+
+	mode := Numeric
+	for i := 0; i < len(text); i++ {
+
+		switch {
+		case text[i] >= '0' && text[i] <= '9':
+		case strings.IndexByte(alphanumeric, text[i]) >= 0:
+			mode = Alphanumeric
+		default:
+			return Byte
+		}
+	}
+
+	return mode
+}
+
+// Encode creates the smallest QR code that holds the text at the given error
+// correction level, in the most compact mode that can hold it.
+func Encode(text string, level Level) (code *Code, err error) {
+
+	if level < Low || level > High {
+
+		err = fmt.Errorf("unknown error correction level %d", level)
+		return
+	}
+
+	mode := modeOf(text)
+
+	// Find the smallest version the data will fit in. The length of the
+	// character count depends on the version, so the data is measured for
+	// each one.
+	version := MinVersion
+	for ; ; version++ {
+
+		if version > MaxVersion {
+
+			err = fmt.Errorf("%w: %d characters at level %s",
+				ErrTooLong, len(text), level,
+			)
+			return
+		}
+
+		if dataBits(mode, len(text), version) <=
+			numDataCodewords(version, level)*8 {
+
+			break
+		}
+	}
+
+	code = &Code{
+		Version: version,
+		Level:   level,
+		Mode:    mode,
+		Size:    version*4 + 17,
+	}
+
+	code.draw(addECC(makeData(text, mode, version, level), version, level))
+
+	return
+}
+
+// dataBits returns the number of bits the text takes up before padding.
+func dataBits(mode Mode, length, version int) (bits int) {
+
+	bits = 4 + mode.countBits(version)
+	switch mode {
+	case Numeric:
+		bits += length/3*10 + [...]int{0, 4, 7}[length%3]
+	case Alphanumeric:
+		bits += length/2*11 + length%2*6
+	case Byte:
+		bits += length * 8
+	}
+
+	return
+}
+
+// bitBuffer collects the bits of the data.
+type bitBuffer []bool
+
+// add appends the lowest n bits of the value, most significant first.
+func (b *bitBuffer) add(value, n int) {
+
+	for i := n - 1; i >= 0; i-- {
+
+		*b = append(*b, value>>i&1 != 0)
+	}
+}
+
+// makeData creates the data codewords of the code, before error correction.
+func makeData(text string, mode Mode, version int, level Level) (data []byte) {
+
+	var bb bitBuffer
+	bb.add(mode.indicator(), 4)
+	bb.add(len(text), mode.countBits(version))
+
+	switch mode {
+	case Numeric:
+		for i := 0; i < len(text); i += 3 {
+
+			n := 3
+			if len(text)-i < 3 {
+
+				n = len(text) - i
+			}
+
+			value := 0
+			for _, c := range text[i : i+n] {
+
+				value = value*10 + int(c-'0')
+			}
+			bb.add(value, n*3+1)
+		}
+
+	case Alphanumeric:
+		for i := 0; i+1 < len(text); i += 2 {
+
+			bb.add(strings.IndexByte(alphanumeric, text[i])*45+
+				strings.IndexByte(alphanumeric, text[i+1]), 11)
+		}
+		if len(text)%2 == 1 {
+
+			bb.add(strings.IndexByte(alphanumeric, text[len(text)-1]), 6)
+		}
+
+	case Byte:
+		for i := 0; i < len(text); i++ {
+
+			bb.add(int(text[i]), 8)
+		}
+	}
+
+	// The data is ended with up to 4 zero bits, then padded to a whole byte
+	// with zero bits, and then to the capacity with alternating pad bytes.
+	capacity := numDataCodewords(version, level) * 8
+	terminator := capacity - len(bb)
+	if terminator > 4 {
+
+		terminator = 4
+	}
+	bb.add(0, terminator)
+	bb.add(0, (8-len(bb)%8)%8)
+
+	data = make([]byte, len(bb)/8, capacity/8)
+	for i, bit := range bb {
+
+		if bit {
+
+			data[i/8] |= 1 << (7 - i%8)
+		}
+	}
+
+	for pad := byte(0xec); len(data) < cap(data); pad ^= 0xec ^ 0x11 {
+
+		data = append(data, pad)
+	}
+
+	return
+}
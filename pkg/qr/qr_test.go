@@ -0,0 +1,321 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestHelloWorld(t *testing.T) {
+
+	// The worked example of the standard, as also used by most tutorials.
+	code, err := Encode("HELLO WORLD", Quartile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.Version != 1 || code.Mode != Alphanumeric {
+		t.Fatalf("got version %d mode %s", code.Version, code.Mode)
+	}
+
+	data := makeData("HELLO WORLD", Alphanumeric, 1, Quartile)
+	expected := []byte{
+		32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236,
+	}
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("got data %v expected %v", data, expected)
+	}
+
+	ecc := rsRemainder(data, rsDivisor(13))
+	expected = []byte{168, 72, 22, 82, 217, 54, 156, 0, 46, 15, 180, 122, 16}
+	if !bytes.Equal(ecc, expected) {
+		t.Fatalf("got error correction %v expected %v", ecc, expected)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+
+	// From the table of format information strings for mask 0.
+	for level, expected := range map[Level]string{
+		Low:      "111011111000100",
+		Medium:   "101010000010010",
+		Quartile: "011010101011111",
+		High:     "001011010001001",
+	} {
+
+		code := &Code{Version: 1, Level: level, Size: 21}
+		code.draw(make([]byte, numRawDataModules(1)/8))
+		code.drawFormatBits(0)
+
+		// Bit 14 is next to the top left finder pattern and bit 0 at the top
+		// of column 8.
+		var got string
+		for i := 14; i >= 0; i-- {
+
+			x, y := 8, i
+			switch {
+			case i >= 9:
+				x, y = 14-i, 8
+			case i == 8:
+				x, y = 7, 8
+			case i >= 6:
+				y = i + 1
+			}
+			got += map[bool]string{true: "1", false: "0"}[code.modules[y][x]]
+		}
+
+		if got != expected {
+			t.Fatalf("level %s got format %s expected %s", level, got, expected)
+		}
+	}
+
+	// The version information of version 7.
+	code := &Code{Version: 7, Level: Low, Size: 45}
+	code.draw(make([]byte, numRawDataModules(7)/8))
+	var got string
+	for i := 17; i >= 0; i-- {
+
+		got += map[bool]string{true: "1", false: "0"}[code.modules[i/3][code.Size-11+i%3]]
+	}
+	if got != "000111110010010100" {
+		t.Fatalf("got version information %s", got)
+	}
+}
+
+func TestCapacity(t *testing.T) {
+
+	for _, c := range []struct {
+		mode    Mode
+		version int
+		level   Level
+		chars   int
+	}{
+		{Numeric, 1, Low, 41},
+		{Alphanumeric, 1, Low, 25},
+		{Byte, 1, Low, 17},
+		{Alphanumeric, 1, High, 10},
+		{Alphanumeric, 10, Medium, 311},
+		{Numeric, 40, Low, 7089},
+		{Alphanumeric, 40, Low, 4296},
+		{Byte, 40, Low, 2953},
+		{Byte, 40, High, 1273},
+	} {
+
+		capacity := numDataCodewords(c.version, c.level) * 8
+		if dataBits(c.mode, c.chars, c.version) > capacity ||
+			dataBits(c.mode, c.chars+1, c.version) <= capacity {
+			t.Fatalf("%s %d-%s should hold %d characters",
+				c.mode, c.version, c.level, c.chars,
+			)
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("A", 4297), Low); err == nil {
+		t.Fatal("too much text gave no error")
+	}
+}
+
+// syndromesZero returns true if the block, data followed by error correction,
+// is a Reed-Solomon code word, which it is if it evaluates to zero at each of
+// the roots of the generator.
+func syndromesZero(block []byte, eccLen int) bool {
+
+	root := byte(1)
+	for i := 0; i < eccLen; i++ {
+
+		var sum byte
+		for _, b := range block {
+
+			sum = gfMultiply(sum, root) ^ b
+		}
+		if sum != 0 {
+
+			return false
+		}
+		root = gfMultiply(root, 0x02)
+	}
+
+	return true
+}
+
+// readCodewords reads the codewords back out of the modules of the code, after
+// undoing the mask, following the placement order of the standard.
+func readCodewords(c *Code) (codewords []byte) {
+
+	c.applyMask(c.Mask)
+	defer c.applyMask(c.Mask)
+
+	var bits []bool
+	for right := c.Size - 1; right >= 1; right -= 2 {
+
+		if right == 6 {
+
+			right = 5
+		}
+
+		for vert := 0; vert < c.Size; vert++ {
+
+			y := vert
+			if (right+1)&2 == 0 {
+
+				y = c.Size - 1 - vert
+			}
+
+			for x := right; x > right-2; x-- {
+
+				if !c.isFunction[y][x] {
+
+					bits = append(bits, c.modules[y][x])
+				}
+			}
+		}
+	}
+
+	codewords = make([]byte, len(bits)/8)
+	for i := range codewords {
+
+		for j := 0; j < 8; j++ {
+
+			if bits[i*8+j] {
+
+				codewords[i] |= 1 << (7 - j)
+			}
+		}
+	}
+
+	return
+}
+
+func TestCodes(t *testing.T) {
+
+	for i, text := range []string{
+		"QNTRLFALGEN75PH72A585LQV32HGD8D3QD6950VVTH89HUHUVGRD8WT7FTET",
+		"QNTRLfalgen75ph72a585lqv32hgd8d3qd6950vvth89huhuvgrd8wt7ftet",
+		"0123456789",
+		strings.Repeat("KITCHENSINK ", 30),
+		strings.Repeat("kitchensink ", 100),
+	} {
+
+		for level := Low; level <= High; level++ {
+
+			code, err := Encode(text, level)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The codewords read back from the code must be those that were
+			// placed, and each block of them must be a valid Reed-Solomon code
+			// word.
+			data := makeData(text, code.Mode, code.Version, level)
+			codewords := addECC(data, code.Version, level)
+			read := readCodewords(code)
+			if !bytes.Equal(read, codewords) {
+				t.Fatalf("text %d level %s read back differently", i, level)
+			}
+
+			// Undo the interleaving, skipping the places of the missing
+			// codewords of the short blocks.
+			numBlock := numBlocks[level][code.Version]
+			eccLen := eccPerBlock[level][code.Version]
+			raw := numRawDataModules(code.Version) / 8
+			shortLen := raw / numBlock
+			numShort := numBlock - raw%numBlock
+			blocks := make([][]byte, numBlock)
+			k := 0
+			for col := 0; col <= shortLen; col++ {
+
+				for b := range blocks {
+
+					if col == shortLen-eccLen && b < numShort {
+						continue
+					}
+					blocks[b] = append(blocks[b], codewords[k])
+					k++
+				}
+			}
+
+			for b, block := range blocks {
+
+				if !syndromesZero(block, eccLen) {
+					t.Fatalf("text %d level %s block %d is not a code word",
+						i, level, b,
+					)
+				}
+			}
+		}
+	}
+
+	// Modes are chosen by the text.
+	for text, mode := range map[string]Mode{
+		"123":      Numeric,
+		"QNTRL123": Alphanumeric,
+		"QNTRLabc": Byte,
+	} {
+
+		code, err := Encode(text, Medium)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code.Mode != mode {
+			t.Fatalf("'%s' got mode %s expected %s", text, code.Mode, mode)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+
+	code, err := Encode("QNTRL", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err = code.WritePNG(&buf, 3); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	width := (code.Size + 2*QuietZone) * 3
+	if img.Bounds().Dx() != width || img.Bounds().Dy() != width {
+		t.Fatalf("got image %v expected %d square", img.Bounds(), width)
+	}
+
+	// The top left corner of the finder pattern is dark, and the quiet zone
+	// is light.
+	if r, _, _, _ := img.At(QuietZone*3, QuietZone*3).RGBA(); r != 0 {
+		t.Fatal("finder pattern is not dark")
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r == 0 {
+		t.Fatal("quiet zone is not light")
+	}
+
+	svg := code.SVG(4)
+	if !strings.HasPrefix(svg, "<svg") ||
+		!strings.Contains(svg, fmt.Sprintf("M%d,%dh1v1h-1z", QuietZone, QuietZone)) {
+		t.Fatalf("unexpected SVG:\n%s", svg)
+	}
+
+	// Each line of the terminal rendering is two rows of modules.
+	lines := strings.Split(strings.TrimSuffix(code.Terminal(false), "\n"), "\n")
+	if len(lines) != (code.Size+2*QuietZone+1)/2 {
+		t.Fatalf("got %d lines", len(lines))
+	}
+	for _, line := range lines {
+
+		if n := len([]rune(line)); n != code.Size+2*QuietZone {
+			t.Fatalf("got line of %d characters", n)
+		}
+	}
+
+	// The third line is the first two rows of the finder pattern: a full row
+	// of dark modules above one that is dark only at the ends.
+	top := []rune(lines[QuietZone/2])[QuietZone:]
+	inverted := []rune(strings.Split(code.Terminal(true), "\n")[QuietZone/2])[QuietZone:]
+	if string(top[:7]) != blockFull+strings.Repeat(blockUpper, 5)+blockFull ||
+		string(inverted[:7]) != blockNone+strings.Repeat(blockLower, 5)+blockNone {
+		t.Fatalf("got '%s' and '%s'", string(top), string(inverted))
+	}
+}
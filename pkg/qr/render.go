@@ -0,0 +1,143 @@
+package qr
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// QuietZone is the width in modules of the light border that scanners need
+// around a code to find it, which is included in all of the renderings.
+const QuietZone = 4
+
+// Image returns the code as an image with each module scale pixels square,
+// which can be encoded in any of the image formats of the standard library.
+func (c *Code) Image(scale int) image.Image {
+
+	if scale < 1 {
+
+		scale = 1
+	}
+
+	width := (c.Size + 2*QuietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, width, width))
+
+	for py := 0; py < width; py++ {
+
+		for px := 0; px < width; px++ {
+
+			shade := color.Gray{Y: 0xff}
+			if c.Dark(px/scale-QuietZone, py/scale-QuietZone) {
+
+				shade.Y = 0
+			}
+			img.SetGray(px, py, shade)
+		}
+	}
+
+	return img
+}
+
+// WritePNG writes the code as a PNG image with each module scale pixels
+// square.
+func (c *Code) WritePNG(w io.Writer, scale int) (err error) {
+
+	return png.Encode(w, c.Image(scale))
+}
+
+// SVG returns the code as an SVG image, with each module scale units square.
+// Being a vector image, it can be printed at any size.
+func (c *Code) SVG(scale int) string {
+
+	if scale < 1 {
+
+		scale = 1
+	}
+
+	width := c.Size + 2*QuietZone
+
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb,
+		"<svg xmlns=\"http://www.w3.org/2000/svg\" version=\"1.1\" "+
+			"viewBox=\"0 0 %d %d\" width=\"%d\" height=\"%d\" "+
+			"stroke=\"none\">\n",
+		width, width, width*scale, width*scale,
+	)
+	sb.WriteString("<rect width=\"100%\" height=\"100%\" fill=\"#ffffff\"/>\n")
+
+	// All of the dark modules are squares in a single path, which keeps the
+	// file small.
+	sb.WriteString("<path fill=\"#000000\" d=\"")
+	first := true
+	for y := 0; y < c.Size; y++ {
+
+		for x := 0; x < c.Size; x++ {
+
+			if !c.Dark(x, y) {
+				continue
+			}
+
+			if !first {
+
+				sb.WriteByte(' ')
+			}
+			first = false
+			_, _ = fmt.Fprintf(&sb, "M%d,%dh1v1h-1z",
+				x+QuietZone, y+QuietZone,
+			)
+		}
+	}
+	sb.WriteString("\"/>\n</svg>\n")
+
+	return sb.String()
+}
+
+// The characters that draw two modules, one above the other, in a terminal.
+const (
+	blockFull  = "█"
+	blockUpper = "▀"
+	blockLower = "▄"
+	blockNone  = " "
+)
+
+// Terminal returns the code drawn with Unicode half block characters, which
+// fit two rows of modules into each line of text, so that the modules are
+// about square in most terminal fonts.
+//
+// The block characters are drawn in the colour of the text, so they are used
+// for the dark modules when the text is dark on a light background. Terminals
+// are more often light text on a dark background, for which invert should be
+// true, so the blocks draw the light modules instead.
+func (c *Code) Terminal(invert bool) string {
+
+	dark := func(x, y int) bool { return c.Dark(x, y) != invert }
+
+	var sb strings.Builder
+	for y := -QuietZone; y < c.Size+QuietZone; y += 2 {
+
+		for x := -QuietZone; x < c.Size+QuietZone; x++ {
+
+			// The bottom half of the last line is past the quiet zone, as
+			// the codes have an odd number of rows, so it is left blank.
+			top := dark(x, y)
+			bottom := y+1 < c.Size+QuietZone && dark(x, y+1)
+
+			switch {
+			case top && bottom:
+				sb.WriteString(blockFull)
+			case top:
+				sb.WriteString(blockUpper)
+			case bottom:
+				sb.WriteString(blockLower)
+			default:
+				sb.WriteString(blockNone)
+			}
+		}
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,149 @@
+package radix
+
+import (
+	"github.com/quanterall/kitchensink/pkg/proto"
+)
+
+// toBits splits the bytes into values of the given number of bits, most
+// significant first, with the last value filled up with zero bits.
+func toBits(input []byte, bits uint) (digits []byte) {
+
+	digits = make([]byte, 0, (len(input)*8+int(bits)-1)/int(bits))
+	mask := uint(1)<<bits - 1
+
+	var acc, n uint
+	for _, b := range input {
+
+		acc = acc<<8 | uint(b)
+		n += 8
+		for n >= bits {
+
+			n -= bits
+			digits = append(digits, byte(acc>>n&mask))
+		}
+		acc &= 1<<n - 1
+	}
+
+	if n > 0 {
+
+		digits = append(digits, byte(acc<<(bits-n)&mask))
+	}
+
+	return
+}
+
+// fromBits joins values of the given number of bits back into bytes. The
+// number of values must be the number toBits gives for the bytes, and the bits
+// that filled up the last value must be zero, so that each string of bytes has
+// only one encoding.
+func fromBits(digits []byte, bits uint) (output []byte, err error) {
+
+	n := len(digits) * int(bits) / 8
+	if (n*8+int(bits)-1)/int(bits) != len(digits) {
+
+		err = proto.Error_INVALID_LENGTH
+		return
+	}
+
+	output = make([]byte, 0, n)
+	var acc, have uint
+	for _, d := range digits {
+
+		acc = acc<<bits | uint(d)
+		have += bits
+		if have >= 8 {
+
+			have -= 8
+			output = append(output, byte(acc>>have))
+		}
+		acc &= 1<<have - 1
+	}
+
+	if acc != 0 {
+
+		err = proto.Error_INVALID_PADDING
+	}
+
+	return
+}
+
+// toRadix converts the bytes, as a big endian number, to its digits in the
+// base, most significant first. Each zero byte at the start becomes a zero
+// digit, as these would otherwise be lost.
+//
+// This is the schoolbook method of long division, repeated for each byte,
+// which takes time in proportion to the square of the length, which is fine
+// for strings that people will read.
+func toRadix(input []byte, base int) (digits []byte) {
+
+	zeros := 0
+	for zeros < len(input) && input[zeros] == 0 {
+
+		zeros++
+	}
+
+	// The digits are built least significant first, multiplying them all by
+	// 256 and adding each byte in turn.
+	var reversed []byte
+	for _, b := range input[zeros:] {
+
+		carry := int(b)
+		for i := range reversed {
+
+			carry += int(reversed[i]) << 8
+			reversed[i] = byte(carry % base)
+			carry /= base
+		}
+
+		for carry > 0 {
+
+			reversed = append(reversed, byte(carry%base))
+			carry /= base
+		}
+	}
+
+	digits = make([]byte, zeros, zeros+len(reversed))
+	for i := len(reversed) - 1; i >= 0; i-- {
+
+		digits = append(digits, reversed[i])
+	}
+
+	return
+}
+
+// fromRadix converts digits in the base, most significant first, back to the
+// bytes, with a zero byte at the start for each zero digit at the start.
+func fromRadix(digits []byte, base int) (output []byte) {
+
+	zeros := 0
+	for zeros < len(digits) && digits[zeros] == 0 {
+
+		zeros++
+	}
+
+	var reversed []byte
+	for _, d := range digits[zeros:] {
+
+		carry := int(d)
+		for i := range reversed {
+
+			carry += int(reversed[i]) * base
+			reversed[i] = byte(carry)
+			carry >>= 8
+		}
+
+		for carry > 0 {
+
+			reversed = append(reversed, byte(carry))
+			carry >>= 8
+		}
+	}
+
+	output = make([]byte, zeros, zeros+len(reversed))
+	for i := len(reversed) - 1; i >= 0; i-- {
+
+		output = append(output, reversed[i])
+	}
+
+	return
+}
@@ -0,0 +1,391 @@
+// Package radix provides codecs with alphabets of any size from 2 to 256
+// characters, such as Base58, base36 and base64url, with the same framing as
+// the based32 package: a Human Readable Part, followed by the encoding of a
+// check length byte, the data, and a check.
+//
+// Alphabets whose size is a power of two, such as base64url, are encoded a
+// fixed number of bits to a character, the same as the standard library
+// encoding/base64 and encoding/base32 packages without padding. Other sizes,
+// such as Base58, can't be split into bits, so the bytes are treated as one
+// big number, which is written out in the base of the alphabet. This loses the
+// zero bytes at the start, which are written as the first character of the
+// alphabet, one for each byte, in the same way as Bitcoin's Base58.
+package radix
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"lukechampine.com/blake3"
+	"strings"
+)
+
+const (
+
+	// Base58Alphabet is the alphabet of Bitcoin's Base58, which leaves out
+	// the characters '0', 'O', 'I' and 'l', which are easily confused.
+	Base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+	// Base36Alphabet is the digits and the lower case letters. As it has only
+	// one case, codes in either case are decoded.
+	Base36Alphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+	// Base64URLAlphabet is the URL and filename safe alphabet of RFC 4648.
+	Base64URLAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz" +
+		"0123456789-_"
+)
+
+const (
+
+	// DefaultCheckLen is the number of check bytes used unless WithCheckLen is
+	// given. It is as many as Bitcoin's Base58Check has, but they are taken
+	// from a Blake3 hash, not a double SHA256, and follow a Human Readable
+	// Part, so the strings can't be checked by Bitcoin software.
+	DefaultCheckLen = 4
+
+	// maxCheckLen is the length of the hash the check is taken from.
+	maxCheckLen = 32
+
+	// separators are the characters that are ignored between the characters
+	// of the data, as for based32.DefaultSeparators, unless they are in the
+	// alphabet.
+	separators = " \t\r\n-"
+)
+
+// The codecs for the common alphabets, with the default check length, named
+// after their alphabets. The Base58 codec is not named Base58Check, as it is
+// not Bitcoin's format of that name.
+//
+// They all have the same Human Readable Part as the based32 package Codec, so
+// a string does not say which of them it was encoded with, and only one of them
+// can be added to a registry.Registry, which rejects the others as ambiguous.
+// Create codecs with New with HRPs of their own to use more than one of them
+// together, or tell them apart with the prefixes of the multibase package.
+var (
+	Base58    = mustNew("Base58", Base58Alphabet, "QNTRL")
+	Base36    = mustNew("Base36", Base36Alphabet, "QNTRL")
+	Base64URL = mustNew("Base64URL", Base64URLAlphabet, "QNTRL")
+)
+
+// mustNew creates one of the package codecs, whose settings are known to be
+// valid.
+func mustNew(name, alphabet, hrp string) *codec.Codec {
+
+	cdc, err := New(name, alphabet, hrp)
+	if err != nil {
+
+		panic(err)
+	}
+
+	return cdc
+}
+
+// options are the settings of a codec that can be changed by an Option.
+type options struct {
+	checkLen int
+}
+
+// Option changes a setting of a codec created by New.
+type Option func(o *options) (err error)
+
+// WithCheckLen sets the number of check bytes, from 1 to 32, which are the
+// start of the Blake3 hash of the data.
+func WithCheckLen(checkLen int) Option {
+
+	return func(o *options) (err error) {
+
+		if checkLen < 1 || checkLen > maxCheckLen {
+
+			err = fmt.Errorf(
+				"check length must be between 1 and %d, got %d",
+				maxCheckLen, checkLen,
+			)
+			return
+		}
+
+		o.checkLen = checkLen
+
+		return
+	}
+}
+
+// validate checks that the alphabet and HRP make a codec that works.
+func validate(name, alphabet, hrp string) (err error) {
+
+	if name == "" {
+
+		err = errors.New("codec name must not be empty")
+		return
+	}
+
+	if len(alphabet) < 2 || len(alphabet) > 256 {
+
+		err = fmt.Errorf(
+			"alphabet must have from 2 to 256 characters, '%s' has %d",
+			alphabet, len(alphabet),
+		)
+		return
+	}
+
+	for i := 0; i < len(alphabet); i++ {
+
+		if alphabet[i] < 33 || alphabet[i] > 126 {
+
+			err = fmt.Errorf(
+				"alphabet '%s' contains invalid character %q at %d",
+				alphabet, alphabet[i], i,
+			)
+			return
+		}
+
+		if j := strings.IndexByte(alphabet[i+1:], alphabet[i]); j >= 0 {
+
+			err = fmt.Errorf(
+				"alphabet '%s' contains '%c' at both %d and %d",
+				alphabet, alphabet[i], i, i+1+j,
+			)
+			return
+		}
+	}
+
+	// Unlike based32, the HRP may use characters of the alphabet, as the
+	// large alphabets leave few that are not, and the decoder knows the HRP
+	// it is looking for.
+	for i := 0; i < len(hrp); i++ {
+
+		if hrp[i] < 33 || hrp[i] > 126 {
+
+			err = fmt.Errorf(
+				"HRP '%s' contains non printable character %q at %d",
+				hrp, hrp[i], i,
+			)
+			return
+		}
+	}
+
+	return
+}
+
+// bitsPerChar returns the number of bits each character stands for if the
+// size of the alphabet is a power of two, or 0 if it is not.
+func bitsPerChar(size int) (bits uint) {
+
+	if size&(size-1) != 0 {
+
+		return 0
+	}
+
+	for size > 1 {
+
+		size >>= 1
+		bits++
+	}
+
+	return
+}
+
+// singleCase returns true if the alphabet has letters of only one case, so
+// that the case of the input can be changed to it when decoding.
+func singleCase(alphabet string) bool {
+
+	return strings.ToLower(alphabet) == alphabet ||
+		strings.ToUpper(alphabet) == alphabet
+}
+
+// New creates a codec with the given name, alphabet and Human Readable Part.
+//
+// The alphabet must have from 2 to 256 different printable ASCII characters,
+// which stand for the values from 0 upwards in order. The HRP can be empty, or
+// any printable ASCII characters.
+func New(name, alphabet, hrp string, opts ...Option) (
+	cdc *codec.Codec, err error,
+) {
+
+	if err = validate(name, alphabet, hrp); err != nil {
+		return
+	}
+
+	o := options{checkLen: DefaultCheckLen}
+	for _, opt := range opts {
+
+		if err = opt(&o); err != nil {
+			return
+		}
+	}
+
+	cdc = &codec.Codec{
+		Name:      name,
+		Charset:   alphabet,
+		HRP:       hrp,
+		CheckName: "Blake3",
+	}
+
+	cdc.MakeCheck = func(input []byte, checkLen int) (output []byte) {
+
+		sum := blake3.Sum256(input)
+		output = make([]byte, checkLen)
+		copy(output, sum[:])
+
+		return
+	}
+
+	// The values of the characters, with -1 for those not in the alphabet.
+	var values [256]int
+	for i := range values {
+
+		values[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+
+		values[alphabet[i]] = i
+	}
+
+	var isSeparator [256]bool
+	for i := 0; i < len(separators); i++ {
+
+		isSeparator[separators[i]] = values[separators[i]] < 0
+	}
+
+	bits := bitsPerChar(len(alphabet))
+	fold := singleCase(alphabet)
+	foldLower := fold && strings.ToLower(alphabet) == alphabet
+
+	cdc.Encoder = func(input []byte) (output string, err error) {
+
+		if len(input) < 1 {
+
+			err = proto.Error_ZERO_LENGTH
+			return
+		}
+
+		// The same layout as based32 version 0: the check length, the input
+		// and the check of the input.
+		raw := make([]byte, 0, len(input)+o.checkLen+1)
+		raw = append(raw, byte(o.checkLen))
+		raw = append(raw, input...)
+		raw = append(raw, cdc.MakeCheck(input, o.checkLen)...)
+
+		var digits []byte
+		if bits > 0 {
+
+			digits = toBits(raw, bits)
+		} else {
+
+			digits = toRadix(raw, len(alphabet))
+		}
+
+		var sb strings.Builder
+		sb.Grow(len(hrp) + len(digits))
+		sb.WriteString(hrp)
+		for _, d := range digits {
+
+			sb.WriteByte(alphabet[d])
+		}
+
+		return sb.String(), nil
+	}
+
+	cdc.Check = func(input []byte) (err error) {
+
+		if len(input) < 1 {
+
+			err = proto.Error_ZERO_LENGTH
+			return
+		}
+
+		// The check length is set for the codec, and has to be checked
+		// against it, as changing the first character of a big number
+		// encoding changes the first byte, and a shorter check would be
+		// easier to pass by chance.
+		checkLen := int(input[0])
+		if checkLen < o.checkLen || len(input) < checkLen+1 {
+
+			err = proto.Error_CHECK_TOO_SHORT
+			return
+		}
+
+		if checkLen != o.checkLen {
+
+			err = proto.Error_CHECK_FAILED
+			return
+		}
+
+		cut := len(input) - checkLen
+		if !bytes.Equal(cdc.MakeCheck(input[1:cut], checkLen), input[cut:]) {
+
+			err = proto.Error_CHECK_FAILED
+		}
+
+		return
+	}
+
+	cdc.Decoder = func(input string) (output []byte, err error) {
+
+		if len(input) < len(hrp) ||
+			(input[:len(hrp)] != hrp &&
+				!(fold && strings.EqualFold(input[:len(hrp)], hrp))) {
+
+			err = proto.Error_INCORRECT_HUMAN_READABLE_PART
+			return
+		}
+
+		// The characters are changed to their values, leaving out the
+		// separators and changing the case to that of the alphabet.
+		digits := make([]byte, 0, len(input)-len(hrp))
+		for i := len(hrp); i < len(input); i++ {
+
+			c := input[i]
+			if isSeparator[c] {
+				continue
+			}
+
+			switch {
+			case foldLower && c >= 'A' && c <= 'Z':
+				c = c - 'A' + 'a'
+			case fold && !foldLower && c >= 'a' && c <= 'z':
+				c = c - 'a' + 'A'
+			}
+
+			if values[c] < 0 {
+
+				err = proto.Error_INVALID_CHARACTER
+				return
+			}
+			digits = append(digits, byte(values[c]))
+		}
+
+		if len(digits) < 1 {
+
+			err = proto.Error_ZERO_LENGTH
+			return
+		}
+
+		var raw []byte
+		if bits > 0 {
+
+			if raw, err = fromBits(digits, bits); err != nil {
+				return
+			}
+		} else {
+
+			raw = fromRadix(digits, len(alphabet))
+		}
+
+		if err = cdc.Check(raw); err != nil {
+			return
+		}
+
+		// A header and check with no data between them is not anything the
+		// encoder produces, as it refuses empty input.
+		if output = raw[1 : len(raw)-int(raw[0])]; len(output) < 1 {
+
+			output, err = nil, proto.Error_ZERO_LENGTH
+		}
+
+		return
+	}
+
+	return
+}
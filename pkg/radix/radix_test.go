@@ -0,0 +1,293 @@
+package radix
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/codecer/codecertest"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"github.com/quanterall/kitchensink/pkg/registry"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// digitsToString writes the digits with the characters of the alphabet.
+func digitsToString(digits []byte, alphabet string) string {
+
+	var sb strings.Builder
+	for _, d := range digits {
+
+		sb.WriteByte(alphabet[d])
+	}
+
+	return sb.String()
+}
+
+func TestBase58Vectors(t *testing.T) {
+
+	// From the test vectors of Bitcoin Core, base58_encode_decode.json.
+	for _, v := range []struct {
+		hex, encoded string
+	}{
+		{"", ""},
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"636363", "aPEr"},
+		{"73696d706c792061206c6f6e6720737472696e67",
+			"2cFupjhnEsSn59qHXstmK2ffpLv2"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647",
+			"1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+		{"516b6fcd0f", "ABnLTmg"},
+		{"bf4f89001e670274dd", "3SEo3LWLoPntC"},
+		{"572e4794", "3EFU7m"},
+		{"ecac89cad93923c02321", "EJDM8drfXA6uyA"},
+		{"10c8511e", "Rt5zm"},
+		{"00000000000000000000", "1111111111"},
+		{"0000287fb4cd", "11233QC4"},
+	} {
+
+		input, err := hex.DecodeString(v.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded := digitsToString(toRadix(input, 58), Base58Alphabet)
+		if encoded != v.encoded {
+			t.Fatalf("%s encoded to '%s' expected '%s'", v.hex, encoded, v.encoded)
+		}
+
+		digits := make([]byte, len(v.encoded))
+		for i := range digits {
+
+			digits[i] = byte(strings.IndexByte(Base58Alphabet, v.encoded[i]))
+		}
+		if decoded := fromRadix(digits, 58); !bytes.Equal(decoded, input) {
+			t.Fatalf("'%s' decoded to %x expected %s", v.encoded, decoded, v.hex)
+		}
+	}
+}
+
+func TestConversions(t *testing.T) {
+
+	for l := 1; l <= 64; l++ {
+
+		input := make([]byte, l)
+		rand.Read(input)
+		input[0] |= 1
+
+		// The power of two alphabets are the same as the standard library
+		// encodings without padding.
+		encoded := digitsToString(toBits(input, 6), Base64URLAlphabet)
+		if expected := base64.RawURLEncoding.EncodeToString(input); encoded !=
+			expected {
+			t.Fatalf("%x encoded to '%s' expected '%s'", input, encoded, expected)
+		}
+
+		// Other bases are the same as the number written in the base, as
+		// long as it does not start with zeros.
+		encoded = digitsToString(toRadix(input, 36), Base36Alphabet)
+		if expected := new(big.Int).SetBytes(input).Text(36); encoded !=
+			expected {
+			t.Fatalf("%x encoded to '%s' expected '%s'", input, encoded, expected)
+		}
+
+		// And the zeros at the start are kept.
+		input[0] = 0
+		for _, base := range []int{3, 10, 36, 58, 255} {
+
+			decoded := fromRadix(toRadix(input, base), base)
+			if !bytes.Equal(decoded, input) {
+				t.Fatalf("base %d gave %x expected %x", base, decoded, input)
+			}
+		}
+		for _, bits := range []uint{1, 2, 3, 4, 5, 6, 7, 8} {
+
+			decoded, err := fromBits(toBits(input, bits), bits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decoded, input) {
+				t.Fatalf("%d bits gave %x expected %x", bits, decoded, input)
+			}
+		}
+	}
+
+	// Only the encoding toBits gives is accepted.
+	if _, err := fromBits([]byte{0, 1}, 6); !errors.Is(
+		err, proto.Error_INVALID_PADDING,
+	) {
+		t.Fatalf("got error %v", err)
+	}
+	if _, err := fromBits([]byte{0, 0, 0, 0, 0}, 6); !errors.Is(
+		err, proto.Error_INVALID_LENGTH,
+	) {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestConformance(t *testing.T) {
+
+	for _, cdc := range []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{"Base58", func(t *testing.T) { codecertest.Run(t, Base58) }},
+		{"Base36", func(t *testing.T) { codecertest.Run(t, Base36) }},
+		{"Base64URL", func(t *testing.T) { codecertest.Run(t, Base64URL) }},
+	} {
+
+		t.Run(cdc.name, cdc.run)
+	}
+}
+
+func TestCodec(t *testing.T) {
+
+	input := []byte("kitchensink")
+
+	// Base36 has one case, so it decodes either, and the separators between
+	// the characters are ignored.
+	encoded, err := Base36.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, "QNTRL") {
+		t.Fatalf("'%s' does not start with the HRP", encoded)
+	}
+	for _, variant := range []string{
+		strings.ToUpper(encoded),
+		strings.ToLower(encoded),
+		encoded[:10] + "-" + encoded[10:15] + " " + encoded[15:],
+	} {
+
+		decoded, err := Base36.Decode(variant)
+		if err != nil {
+			t.Fatalf("'%s': %v", variant, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Fatalf("'%s' gave %x expected %x", variant, decoded, input)
+		}
+	}
+
+	// Base58 has both cases, so it doesn't.
+	if encoded, err = Base58.Encode(input); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Base58.Decode(strings.ToUpper(encoded)); err == nil {
+		t.Fatal("upper case Base58 decoded")
+	}
+
+	// The check length can be changed.
+	long, err := New("Long", Base58Alphabet, "", WithCheckLen(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var longer string
+	if longer, err = long.Encode(input); err != nil {
+		t.Fatal(err)
+	}
+	if len(longer) <= len(encoded)-len("QNTRL") {
+		t.Fatalf("'%s' is no longer than '%s'", longer, encoded)
+	}
+	if _, err = long.Decode(longer); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only a header and a check, with a correct check for no data.
+	raw := append([]byte{DefaultCheckLen}, Base58.MakeCheck(nil, DefaultCheckLen)...)
+	empty := "QNTRL" + digitsToString(toRadix(raw, 58), Base58Alphabet)
+
+	for _, c := range []struct {
+		input string
+		err   error
+	}{
+		{"", proto.Error_INCORRECT_HUMAN_READABLE_PART},
+		{empty, proto.Error_ZERO_LENGTH},
+		{"QNTRX" + encoded[5:], proto.Error_INCORRECT_HUMAN_READABLE_PART},
+		{"QNTRL", proto.Error_ZERO_LENGTH},
+		{encoded + "0", proto.Error_INVALID_CHARACTER},
+		{encoded[:len(encoded)-1] + "1", proto.Error_CHECK_FAILED},
+	} {
+
+		if _, err = Base58.Decode(c.input); !errors.Is(err, c.err) {
+			t.Fatalf("'%s' gave error %v expected %v", c.input, err, c.err)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+
+	for _, c := range []struct {
+		name, alphabet, hrp string
+		opts                []Option
+	}{
+		{"", Base58Alphabet, "", nil},
+		{"One", "a", "", nil},
+		{"Long", strings.Repeat("a", 257), "", nil},
+		{"Duplicate", "abca", "", nil},
+		{"Space", "ab c", "", nil},
+		{"HRP", "abc", "Q\tN", nil},
+		{"NoCheck", "abc", "", []Option{WithCheckLen(0)}},
+		{"LongCheck", "abc", "", []Option{WithCheckLen(33)}},
+	} {
+
+		if _, err := New(c.name, c.alphabet, c.hrp, c.opts...); err == nil {
+			t.Fatalf("'%s' codec was created", c.name)
+		}
+	}
+
+	// Alphabets of any size in between work, including the sizes that are
+	// powers of two.
+	for _, size := range []int{2, 3, 8, 16, 57, 64, 94} {
+
+		var sb strings.Builder
+		for c := byte(33); sb.Len() < size; c++ {
+
+			sb.WriteByte(c)
+		}
+
+		cdc, err := New("Size", sb.String(), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		input := []byte{0, 0, 1, 2, 3}
+		encoded, err := cdc.Encode(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := cdc.Decode(encoded)
+		if err != nil {
+			t.Fatalf("size %d '%s': %v", size, encoded, err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Fatalf("size %d gave %x expected %x", size, decoded, input)
+		}
+	}
+}
+
+func TestSharedHRP(t *testing.T) {
+
+	// The package codecs can't be told apart by their HRP, so a registry only
+	// takes one of them, and codecs with HRPs of their own can be added.
+	r := registry.New()
+	if err := r.Register(Base58); err != nil {
+		t.Fatal(err)
+	}
+	for _, cdc := range []*codec.Codec{Base36, Base64URL} {
+
+		if err := r.Register(cdc); err == nil {
+			t.Fatalf("%s was registered with %s", cdc.Name, Base58.Name)
+		}
+	}
+
+	own, err := New("Base36Own", Base36Alphabet, "B36")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Register(own); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,212 @@
+// Package registry provides a collection of codecs that are selected
+// automatically by the Human Readable Part of the string being decoded
+//
+// This allows an application to use several codecs at once, for example for
+// main and test networks, or for different types of entity, and decode strings
+// without having to know in advance which codec created them.
+//
+// The HRP is a plain string prefix, so registering HRPs where one is the start
+// of another is only permitted when the two can't be confused, which is when
+// the extra characters of the longer one can't appear in the data of the
+// shorter one's encoding.
+package registry
+
+import (
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry is a set of codecs indexed by name and by HRP. It is safe for
+// concurrent use, though normally all of the codecs are registered at startup
+// and only Decode is used afterwards.
+type Registry struct {
+	mx     sync.RWMutex
+	byName map[string]*codec.Codec
+
+	// byHRP is kept sorted by HRP length, longest first, so that the first
+	// matching prefix found is also the longest.
+	byHRP []*codec.Codec
+}
+
+// New creates an empty Registry.
+func New() (r *Registry) {
+
+	return &Registry{byName: make(map[string]*codec.Codec)}
+}
+
+// folds returns true if the codec accepts its strings in either case, which
+// codecs with a charset of one case do.
+func folds(cdc *codec.Codec) bool {
+
+	return strings.ToLower(cdc.Charset) == cdc.Charset ||
+		strings.ToUpper(cdc.Charset) == cdc.Charset
+}
+
+// hasPrefix returns true if the input starts with the HRP of the codec, in
+// either case if the codec folds case.
+func hasPrefix(input string, cdc *codec.Codec) bool {
+
+	if folds(cdc) {
+
+		return strings.HasPrefix(
+			strings.ToUpper(input), strings.ToUpper(cdc.HRP),
+		)
+	}
+
+	return strings.HasPrefix(input, cdc.HRP)
+}
+
+// ambiguous returns true if a string encoded with the codec with the shorter
+// HRP could start with the HRP of the other codec. If either codec folds case,
+// the HRPs and the charset are compared in either case, as the strings may be
+// written in either.
+func ambiguous(a, b *codec.Codec) bool {
+
+	short, long := a, b
+	if len(short.HRP) > len(long.HRP) {
+
+		short, long = long, short
+	}
+
+	fold := folds(short) || folds(long)
+	shortHRP, longHRP, charset := short.HRP, long.HRP, short.Charset
+	if fold {
+
+		shortHRP, longHRP = strings.ToUpper(shortHRP), strings.ToUpper(longHRP)
+		charset = strings.ToUpper(charset)
+	}
+
+	if !strings.HasPrefix(longHRP, shortHRP) {
+
+		return false
+	}
+
+	// When the HRPs are the same there is no way to tell the codecs apart.
+	// Otherwise, if every remaining character of the longer HRP is in the
+	// charset of the shorter, its encoded data could begin with them.
+	for _, c := range longHRP[len(shortHRP):] {
+
+		if !strings.ContainsRune(charset, c) {
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// Register adds a codec to the registry. An error is returned if the name is
+// already registered, or if the HRP is the same as, or can be confused with,
+// the HRP of a codec already registered.
+func (r *Registry) Register(cdc *codec.Codec) (err error) {
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if _, ok := r.byName[cdc.Name]; ok {
+
+		err = fmt.Errorf("codec named '%s' is already registered", cdc.Name)
+		return
+	}
+
+	for _, c := range r.byHRP {
+
+		if ambiguous(c, cdc) {
+
+			err = fmt.Errorf(
+				"HRP '%s' of codec '%s' is ambiguous with HRP '%s' of codec '%s'",
+				cdc.HRP, cdc.Name, c.HRP, c.Name,
+			)
+			return
+		}
+	}
+
+	r.byName[cdc.Name] = cdc
+	r.byHRP = append(r.byHRP, cdc)
+	sort.SliceStable(
+		r.byHRP, func(i, j int) bool {
+			return len(r.byHRP[i].HRP) > len(r.byHRP[j].HRP)
+		},
+	)
+
+	return
+}
+
+// Get returns the codec registered with the given name.
+func (r *Registry) Get(name string) (cdc *codec.Codec, ok bool) {
+
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	cdc, ok = r.byName[name]
+
+	return
+}
+
+// Codecs returns the registered codecs, ordered by the length of their HRP,
+// longest first.
+func (r *Registry) Codecs() (codecs []*codec.Codec) {
+
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	codecs = make([]*codec.Codec, len(r.byHRP))
+	copy(codecs, r.byHRP)
+
+	return
+}
+
+// Match returns the codec with the longest HRP that is a prefix of the input,
+// in either case for codecs that fold case.
+func (r *Registry) Match(input string) (cdc *codec.Codec, err error) {
+
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	for _, c := range r.byHRP {
+
+		if hasPrefix(input, c) {
+
+			cdc = c
+			return
+		}
+	}
+
+	err = proto.Error_INCORRECT_HUMAN_READABLE_PART
+
+	return
+}
+
+// Decode finds the codec that matches the HRP of the input and decodes it,
+// returning the codec that was used along with the output.
+func (r *Registry) Decode(input string) (
+	cdc *codec.Codec, output []byte, err error,
+) {
+
+	if cdc, err = r.Match(input); err != nil {
+		return
+	}
+
+	output, err = cdc.Decode(input)
+
+	return
+}
+
+// Default is the registry used by the package level functions.
+var Default = New()
+
+// Register adds a codec to the Default registry.
+func Register(cdc *codec.Codec) (err error) { return Default.Register(cdc) }
+
+// Get returns the codec with the given name from the Default registry.
+func Get(name string) (*codec.Codec, bool) { return Default.Get(name) }
+
+// Decode decodes the input with the matching codec in the Default registry.
+func Decode(input string) (*codec.Codec, []byte, error) {
+
+	return Default.Decode(input)
+}
@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"bytes"
+	"github.com/quanterall/kitchensink/pkg/based32"
+	"github.com/quanterall/kitchensink/pkg/bech32"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"strings"
+	"testing"
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+func newCodec(t *testing.T, name, hrp string) *codec.Codec {
+
+	cdc, err := based32.NewCodec(name, charset, hrp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cdc
+}
+
+func TestRegistry(t *testing.T) {
+
+	r := New()
+
+	testnet := newCodec(t, "Testnet", "TESTNET")
+	b32, err := bech32.New("Bech32", "kitchen", bech32.Bech32m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cdc := range []*codec.Codec{based32.Codec, testnet, b32} {
+
+		if err = r.Register(cdc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each of these must be rejected, for a duplicate name, a duplicate HRP,
+	// an HRP that could be the start of the data of the QNTRL codec, and one
+	// whose data could start with the end of the Bech32 codec's HRP. The last
+	// two can't be created with based32.NewCodec, which refuses HRPs that
+	// overlap the charset, so they are constructed directly. The HRPs of
+	// codecs that fold case are compared in either case, as their strings
+	// can be written in either.
+	for _, cdc := range []*codec.Codec{
+		newCodec(t, "Base32Check", "OTHER"),
+		newCodec(t, "Duplicate", "QNTRL"),
+		{Name: "Ambiguous", HRP: "QNTRLqp", Charset: charset},
+		{Name: "Shorter", HRP: "kitche", Charset: charset},
+		{Name: "Folded", HRP: "qntrl", Charset: charset},
+		{Name: "Testnet2", HRP: "TestNet", Charset: charset},
+	} {
+
+		if err = r.Register(cdc); err == nil {
+			t.Fatalf("registered '%s' with HRP '%s'", cdc.Name, cdc.HRP)
+		}
+	}
+
+	input := []byte("registry test input")
+	for _, cdc := range []*codec.Codec{based32.Codec, testnet, b32} {
+
+		var encoded string
+		if encoded, err = cdc.Encode(input); err != nil {
+			t.Fatal(err)
+		}
+
+		found, output, err := r.Decode(encoded)
+		if err != nil {
+			t.Fatalf("'%s': %v", encoded, err)
+		}
+		if found != cdc {
+			t.Fatalf("'%s' decoded by %s expected %s", encoded, found.Name, cdc.Name)
+		}
+		if !bytes.Equal(output, input) {
+			t.Fatalf("got %x expected %x", output, input)
+		}
+	}
+
+	if _, _, err = r.Decode("NOTREGISTERED"); err !=
+		proto.Error_INCORRECT_HUMAN_READABLE_PART {
+
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if cdc, ok := r.Get("Testnet"); !ok || cdc != testnet {
+		t.Fatal("codec not found by name")
+	}
+}
+
+func TestRegistryCaseFolding(t *testing.T) {
+
+	// The upper case strings of the first codec start with the HRP of the
+	// second, which they must not be sent to, so the second can't be
+	// registered.
+	upper, err := based32.NewCodec("Upper", charset, "QNTRL",
+		based32.WithUppercase(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := New()
+	if err = r.Register(upper); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Register(newCodec(t, "Longer", "QNTRLT")); err == nil {
+		t.Fatal("registered HRP 'QNTRLT' with HRP 'QNTRL' in upper case")
+	}
+
+	input := []byte{0xff, 0}
+	encoded, err := upper.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Either case is matched, as the codec decodes either.
+	for _, s := range []string{encoded, strings.ToLower(encoded)} {
+
+		found, output, err := r.Decode(s)
+		if err != nil {
+			t.Fatalf("'%s': %v", s, err)
+		}
+		if found != upper || !bytes.Equal(output, input) {
+			t.Fatalf("'%s' decoded by %s to %x", s, found.Name, output)
+		}
+	}
+}
@@ -0,0 +1,553 @@
+// Package tlv packs a small record of typed fields into the bytes of a single
+// code, and unpacks it again, either into a map of fields or into a Go struct
+// with tagged fields, such as:
+//
+//	type Ticket struct {
+//		Type    uint8     `tlv:"1"`
+//		ID      []byte    `tlv:"2"`
+//		Expires time.Time `tlv:"3,omitempty"`
+//	}
+//
+// TLV stands for Tag, Length, Value. Each field is written as a key, which is
+// the tag number of the field and its kind, followed by the value. Values of
+// the Uint and Time kinds are varints, as in the standard library
+// encoding/binary package, which are only as long as the number needs, and the
+// others are a varint length followed by that many bytes.
+//
+// The format is forward compatible: the lowest bit of the kind says which of
+// the two ways its value is written, so a field of a kind added in a later
+// version can still be skipped, and fields with tags that the struct being
+// decoded into doesn't have are ignored. When decoding into Fields, fields of
+// unknown kinds are kept as Unknown values, which are written back out as they
+// were.
+package tlv
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the type of the value of a field.
+type Kind uint8
+
+const (
+
+	// Uint is an unsigned integer of up to 64 bits.
+	Uint Kind = 0
+
+	// Bytes is a byte slice.
+	Bytes Kind = 1
+
+	// Time is a time in whole seconds since the Unix epoch, which may be
+	// negative, and is decoded in UTC.
+	Time Kind = 2
+
+	// String is a string.
+	String Kind = 3
+
+	// kindBits is the number of bits of the key used for the kind.
+	kindBits = 3
+
+	// lengthPrefixed is the bit of the kind that is set for kinds whose values
+	// have a length prefix.
+	lengthPrefixed = 1
+)
+
+// MaxTag is the largest tag number that can be used.
+const MaxTag = 1<<(64-kindBits) - 1
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+
+	switch k {
+	case Uint:
+		return "Uint"
+	case Bytes:
+		return "Bytes"
+	case Time:
+		return "Time"
+	case String:
+		return "String"
+	default:
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+var (
+
+	// ErrTruncated is returned when the data ends in the middle of a field.
+	ErrTruncated = errors.New("tlv data ends in the middle of a field")
+
+	// ErrDuplicateTag is returned when the same tag appears more than once,
+	// in data being decoded or in the fields of a struct.
+	ErrDuplicateTag = errors.New("tlv tag appears more than once")
+
+	// ErrKind is returned when a field in the data is of a different kind to
+	// the struct field with its tag.
+	ErrKind = errors.New("tlv field is of the wrong kind")
+)
+
+// Fields is a record as a map from tag numbers to values. When marshalling,
+// the values can be any unsigned integer type, []byte, string, time.Time or
+// Unknown. When unmarshalling, the values are uint64, []byte, string,
+// time.Time, or Unknown for fields of kinds this package does not know.
+type Fields map[uint64]interface{}
+
+// Unknown is the value of a field of a kind this package does not know, which
+// will have been written by a later version.
+type Unknown struct {
+	Kind Kind
+
+	// Data is the value exactly as it was found, not including the length
+	// prefix if the kind has one.
+	Data []byte
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// field is a tag and value waiting to be written.
+type field struct {
+	tag   uint64
+	value reflect.Value
+}
+
+// Marshal packs the record v, which is either Fields or a struct or pointer to
+// a struct with tagged fields, into bytes. Fields are written in order of their
+// tags, so the same record always gives the same bytes.
+//
+// Struct fields are written if they have a tag of the form `tlv:"<number>"`,
+// unless they have the omitempty option, as in `tlv:"<number>,omitempty"`, and
+// are the zero value of their type. The Go types of the fields must be one of
+// those listed for Fields.
+func Marshal(v interface{}) (data []byte, err error) {
+
+	var fields []field
+	if fields, err = collect(v); err != nil {
+		return
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].tag < fields[j].tag
+	})
+
+	for i, f := range fields {
+
+		if i > 0 && fields[i-1].tag == f.tag {
+
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateTag, f.tag)
+		}
+
+		if data, err = appendField(data, f); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// collect gathers the fields to be written from Fields or a struct.
+func collect(v interface{}) (fields []field, err error) {
+
+	if m, ok := v.(Fields); ok {
+
+		for tag, value := range m {
+
+			fields = append(fields, field{tag, reflect.ValueOf(value)})
+		}
+
+		return
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+
+		err = fmt.Errorf("tlv can't marshal %T, only Fields or a struct", v)
+		return
+	}
+
+	for i := 0; i < rv.NumField(); i++ {
+
+		tag, omitEmpty, ok, e := parseTag(rv.Type().Field(i))
+		if e != nil {
+
+			return nil, e
+		}
+
+		if !ok || omitEmpty && rv.Field(i).IsZero() {
+			continue
+		}
+
+		fields = append(fields, field{tag, rv.Field(i)})
+	}
+
+	return
+}
+
+// parseTag reads the tlv tag of a struct field, returning false if it doesn't
+// have one.
+func parseTag(sf reflect.StructField) (tag uint64, omitEmpty, ok bool,
+	err error,
+) {
+
+	s, ok := sf.Tag.Lookup("tlv")
+	if !ok || s == "-" {
+
+		return 0, false, false, nil
+	}
+
+	// The values of unexported fields can't be read or set by the reflect
+	// package.
+	if !sf.IsExported() {
+
+		err = fmt.Errorf("field %s has a tlv tag but is not exported", sf.Name)
+		return
+	}
+
+	parts := strings.Split(s, ",")
+	if tag, err = strconv.ParseUint(parts[0], 10, 64); err != nil ||
+		tag > MaxTag {
+
+		err = fmt.Errorf("field %s has invalid tlv tag '%s'", sf.Name, s)
+		return
+	}
+
+	for _, option := range parts[1:] {
+
+		if option != "omitempty" {
+
+			err = fmt.Errorf(
+				"field %s has unknown tlv tag option '%s'", sf.Name, option,
+			)
+			return
+		}
+		omitEmpty = true
+	}
+
+	return tag, omitEmpty, true, nil
+}
+
+// kindOf returns the kind used for values of a Go type.
+func kindOf(t reflect.Type) (kind Kind, ok bool) {
+
+	switch {
+	case t == timeType:
+		return Time, true
+	case t.Kind() == reflect.String:
+		return String, true
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return Bytes, true
+	}
+
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+
+		return Uint, true
+	}
+
+	return
+}
+
+// appendField appends the key and value of a field to the data.
+func appendField(data []byte, f field) (output []byte, err error) {
+
+	if f.tag > MaxTag {
+
+		return data, fmt.Errorf("tlv tag %d is larger than %d", f.tag, MaxTag)
+	}
+
+	if !f.value.IsValid() {
+
+		return data, fmt.Errorf("tlv field %d has no value", f.tag)
+	}
+
+	if u, ok := f.value.Interface().(Unknown); ok {
+
+		if u.Kind >= 1<<kindBits {
+
+			return data, fmt.Errorf("tlv field %d has invalid kind %d",
+				f.tag, u.Kind,
+			)
+		}
+
+		data = appendUvarint(data, f.tag<<kindBits|uint64(u.Kind))
+		if u.Kind&lengthPrefixed != 0 {
+
+			data = appendUvarint(data, uint64(len(u.Data)))
+		}
+
+		return append(data, u.Data...), nil
+	}
+
+	kind, ok := kindOf(f.value.Type())
+	if !ok {
+
+		return data, fmt.Errorf(
+			"tlv field %d is of type %s which can't be encoded",
+			f.tag, f.value.Type(),
+		)
+	}
+
+	data = appendUvarint(data, f.tag<<kindBits|uint64(kind))
+
+	switch kind {
+	case Uint:
+		data = appendUvarint(data, f.value.Uint())
+	case Time:
+		data = appendVarint(
+			data, f.value.Interface().(time.Time).Unix(),
+		)
+	case Bytes:
+		data = appendUvarint(data, uint64(f.value.Len()))
+		data = append(data, f.value.Bytes()...)
+	case String:
+		data = appendUvarint(data, uint64(f.value.Len()))
+		data = append(data, f.value.String()...)
+	}
+
+	return data, nil
+}
+
+// Unmarshal unpacks the data into v, which is either a pointer to Fields or a
+// pointer to a struct with tagged fields as described for Marshal.
+//
+// Fields in the data with tags the struct doesn't have are skipped, and struct
+// fields with tags that aren't in the data are left as they are.
+func Unmarshal(data []byte, v interface{}) (err error) {
+
+	var set func(tag uint64, kind Kind, value interface{}) error
+
+	switch dst := v.(type) {
+	case *Fields:
+
+		if *dst == nil {
+
+			*dst = make(Fields)
+		}
+		set = func(tag uint64, _ Kind, value interface{}) error {
+
+			(*dst)[tag] = value
+			return nil
+		}
+
+	default:
+
+		if set, err = structSetter(v); err != nil {
+			return
+		}
+	}
+
+	seen := make(map[uint64]bool)
+	for len(data) > 0 {
+
+		var tag uint64
+		var kind Kind
+		var value interface{}
+		if tag, kind, value, data, err = readField(data); err != nil {
+			return
+		}
+
+		if seen[tag] {
+
+			return fmt.Errorf("%w: %d", ErrDuplicateTag, tag)
+		}
+		seen[tag] = true
+
+		if err = set(tag, kind, value); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// structSetter returns a function that sets the field of the struct pointed
+// to by v that has the given tag.
+func structSetter(v interface{}) (
+	set func(tag uint64, kind Kind, value interface{}) error, err error,
+) {
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+
+		err = fmt.Errorf(
+			"tlv can't unmarshal into %T, only *Fields or a struct pointer", v,
+		)
+		return
+	}
+	rv = rv.Elem()
+
+	byTag := make(map[uint64]int)
+	for i := 0; i < rv.NumField(); i++ {
+
+		tag, _, ok, e := parseTag(rv.Type().Field(i))
+		if e != nil {
+
+			return nil, e
+		}
+
+		if !ok {
+			continue
+		}
+
+		if _, dup := byTag[tag]; dup {
+
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateTag, tag)
+		}
+		byTag[tag] = i
+	}
+
+	set = func(tag uint64, kind Kind, value interface{}) error {
+
+		i, ok := byTag[tag]
+		if !ok {
+			return nil
+		}
+
+		fv := rv.Field(i)
+		if k, ok := kindOf(fv.Type()); !ok || k != kind {
+
+			return fmt.Errorf(
+				"%w: tag %d is %s but field %s is %s",
+				ErrKind, tag, kind, rv.Type().Field(i).Name, fv.Type(),
+			)
+		}
+
+		switch kind {
+		case Uint:
+			u := value.(uint64)
+			if fv.OverflowUint(u) {
+
+				return fmt.Errorf(
+					"tlv value %d of tag %d is too large for field %s",
+					u, tag, rv.Type().Field(i).Name,
+				)
+			}
+			fv.SetUint(u)
+		case Bytes:
+			fv.SetBytes(value.([]byte))
+		case String:
+			fv.SetString(value.(string))
+		case Time:
+			fv.Set(reflect.ValueOf(value))
+		}
+
+		return nil
+	}
+
+	return
+}
+
+// readField reads one field from the front of the data, returning the rest.
+func readField(data []byte) (tag uint64, kind Kind, value interface{},
+	rest []byte, err error,
+) {
+
+	key, n := binary.Uvarint(data)
+	if n <= 0 {
+
+		err = ErrTruncated
+		return
+	}
+	data = data[n:]
+	tag, kind = key>>kindBits, Kind(key&(1<<kindBits-1))
+
+	// Values without a length prefix are a single varint.
+	if kind&lengthPrefixed == 0 {
+
+		var u uint64
+		if u, n = binary.Uvarint(data); n <= 0 {
+
+			err = ErrTruncated
+			return
+		}
+
+		switch kind {
+		case Uint:
+			value = u
+		case Time:
+			// The same bytes read as a signed varint.
+			s, _ := binary.Varint(data)
+			value = time.Unix(s, 0).UTC()
+		default:
+			value = Unknown{Kind: kind, Data: copyBytes(data[:n])}
+		}
+
+		return tag, kind, value, data[n:], nil
+	}
+
+	length, n := binary.Uvarint(data)
+	if n <= 0 || length > uint64(len(data)-n) {
+
+		err = ErrTruncated
+		return
+	}
+	data = data[n:]
+	b := copyBytes(data[:length])
+
+	switch kind {
+	case Bytes:
+		value = b
+	case String:
+		value = string(b)
+	default:
+		value = Unknown{Kind: kind, Data: b}
+	}
+
+	return tag, kind, value, data[length:], nil
+}
+
+// appendUvarint appends the unsigned varint encoding of u to the data.
+func appendUvarint(data []byte, u uint64) []byte {
+
+	var buf [binary.MaxVarintLen64]byte
+	return append(data, buf[:binary.PutUvarint(buf[:], u)]...)
+}
+
+// appendVarint appends the signed varint encoding of i to the data.
+func appendVarint(data []byte, i int64) []byte {
+
+	var buf [binary.MaxVarintLen64]byte
+	return append(data, buf[:binary.PutVarint(buf[:], i)]...)
+}
+
+// copyBytes returns a copy of b, so that decoded values don't share memory
+// with the data they were decoded from.
+func copyBytes(b []byte) []byte {
+
+	return append([]byte{}, b...)
+}
+
+// Encode marshals the record v and encodes it with the codec.
+func Encode(cdc *codec.Codec, v interface{}) (output string, err error) {
+
+	var data []byte
+	if data, err = Marshal(v); err != nil {
+		return
+	}
+
+	return cdc.Encode(data)
+}
+
+// Decode decodes the input with the codec and unmarshals the record into v.
+func Decode(cdc *codec.Codec, input string, v interface{}) (err error) {
+
+	var data []byte
+	if data, err = cdc.Decode(input); err != nil {
+		return
+	}
+
+	return Unmarshal(data, v)
+}
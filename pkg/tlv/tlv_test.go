@@ -0,0 +1,193 @@
+package tlv
+
+import (
+	"bytes"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/based32"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type ticket struct {
+	Type    uint8     `tlv:"1"`
+	ID      []byte    `tlv:"2"`
+	Owner   string    `tlv:"3"`
+	Expires time.Time `tlv:"4,omitempty"`
+	Note    string    `tlv:"-"`
+}
+
+// ticketV2 is a later version of ticket, with an extra field and one removed.
+type ticketV2 struct {
+	Type   uint64 `tlv:"1"`
+	ID     []byte `tlv:"2"`
+	Seats  uint16 `tlv:"5"`
+	Refund string `tlv:"6,omitempty"`
+}
+
+func TestStruct(t *testing.T) {
+
+	in := ticket{
+		Type:    3,
+		ID:      []byte{0xde, 0xad, 0xbe, 0xef},
+		Owner:   "kitchensink",
+		Expires: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		Note:    "not encoded",
+	}
+
+	encoded, err := Encode(based32.Codec, &in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out ticket
+	if err = Decode(based32.Codec, encoded, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	in.Note = ""
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("got %+v expected %+v", out, in)
+	}
+
+	// The same record always gives the same bytes.
+	again, err := Encode(based32.Codec, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != encoded {
+		t.Fatalf("got '%s' then '%s'", encoded, again)
+	}
+
+	// A later version of the record can be read by the earlier one and vice
+	// versa, ignoring the fields each doesn't know.
+	var v2 ticketV2
+	if err = Decode(based32.Codec, encoded, &v2); err != nil {
+		t.Fatal(err)
+	}
+	if v2.Type != 3 || !bytes.Equal(v2.ID, in.ID) {
+		t.Fatalf("got %+v", v2)
+	}
+
+	v2.Seats = 4
+	if encoded, err = Encode(based32.Codec, v2); err != nil {
+		t.Fatal(err)
+	}
+	out = ticket{}
+	if err = Decode(based32.Codec, encoded, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Type != 3 || !bytes.Equal(out.ID, in.ID) || !out.Expires.IsZero() {
+		t.Fatalf("got %+v", out)
+	}
+}
+
+func TestFields(t *testing.T) {
+
+	expires := time.Unix(1893456000, 0).UTC()
+	in := Fields{
+		1: uint8(7),
+		2: []byte{1, 2, 3},
+		3: "owner",
+		4: expires,
+		// Fields of kinds from later versions are kept as they are.
+		9:  Unknown{Kind: 5, Data: []byte("future")},
+		10: Unknown{Kind: 6, Data: []byte{0xac, 0x02}},
+	}
+
+	data, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out Fields
+	if err = Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	in[1] = uint64(7)
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("got %#v expected %#v", out, in)
+	}
+
+	// Unknown fields are written back out as they were found.
+	again, err := Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Fatalf("got %x expected %x", again, data)
+	}
+
+	// A struct skips fields of kinds it doesn't know.
+	var tk ticket
+	if err = Unmarshal(data, &tk); err != nil {
+		t.Fatal(err)
+	}
+	if tk.Type != 7 || tk.Owner != "owner" || !tk.Expires.Equal(expires) {
+		t.Fatalf("got %+v", tk)
+	}
+}
+
+func TestErrors(t *testing.T) {
+
+	data, err := Marshal(Fields{1: "owner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var f Fields
+	for i := 1; i < len(data); i++ {
+
+		if err = Unmarshal(data[:i], &f); !errors.Is(err, ErrTruncated) {
+			t.Fatalf("truncated to %x gave error %v", data[:i], err)
+		}
+	}
+
+	if err = Unmarshal(append(data, data...), &f); !errors.Is(
+		err, ErrDuplicateTag,
+	) {
+		t.Fatalf("duplicate tag gave error %v", err)
+	}
+
+	// Tag 1 is a string in the data but a number in the struct.
+	var tk ticket
+	if err = Unmarshal(data, &tk); !errors.Is(err, ErrKind) {
+		t.Fatalf("wrong kind gave error %v", err)
+	}
+
+	// Numbers too large for the field are rejected.
+	if data, err = Marshal(Fields{1: uint64(256)}); err != nil {
+		t.Fatal(err)
+	}
+	if err = Unmarshal(data, &tk); err == nil {
+		t.Fatal("overflow gave no error")
+	}
+
+	for _, v := range []interface{}{
+		Fields{1: 1.5},
+		Fields{1: -1},
+		Fields{MaxTag + 1: "too large"},
+		Fields{1: Unknown{Kind: 8}},
+		42,
+		struct {
+			A string `tlv:"1"`
+			B string `tlv:"1"`
+		}{},
+		struct {
+			A string `tlv:"one"`
+		}{},
+		struct {
+			a string `tlv:"1"`
+		}{},
+	} {
+
+		if _, err = Marshal(v); err == nil {
+			t.Fatalf("marshalling %#v gave no error", v)
+		}
+	}
+
+	if err = Unmarshal(nil, tk); err == nil {
+		t.Fatal("unmarshalling into a struct value gave no error")
+	}
+}
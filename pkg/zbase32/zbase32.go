@@ -0,0 +1,117 @@
+// Package zbase32 provides a codec for z-base-32, the human oriented base32
+// encoding designed by Zooko Wilcox-O'Hearn, as described at
+// https://philzimmermann.com/docs/human-oriented-base-32-encoding.txt.
+//
+// The alphabet is ordered so that the symbols which are easiest to read,
+// write and say are the ones used most often, and it is lower case only, as
+// lower case letters are easier to tell apart. Upper case codes are still
+// decoded, as people will write them.
+//
+// Bytes are encoded five bits to a symbol, most significant first, with the
+// last symbol filled up with zero bits and no padding. The specification also
+// allows encoding a number of bits that is not a multiple of 8, which is not
+// supported here, as a codec.Codec encodes bytes.
+//
+// There is no check in z-base-32, so mistakes in copying a code are not found
+// by decoding it, unless they make it an invalid length.
+package zbase32
+
+import (
+	"github.com/quanterall/kitchensink/pkg/bech32"
+	"github.com/quanterall/kitchensink/pkg/codec"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"strings"
+)
+
+// Alphabet is the 32 symbols of z-base-32 in the order of their values.
+const Alphabet = "ybndrfg8ejkmcpqxot1uwisza345h769"
+
+// Codec is the z-base-32 codec.
+var Codec = New("ZBase32")
+
+// values maps each character, in either case, to its value, with -1 for those
+// that are not symbols.
+var values = func() (values [256]int) {
+
+	for i := range values {
+
+		values[i] = -1
+	}
+
+	for i := 0; i < len(Alphabet); i++ {
+
+		c := Alphabet[i]
+		values[c] = i
+		if c >= 'a' && c <= 'z' {
+
+			values[c-'a'+'A'] = i
+		}
+	}
+
+	return
+}()
+
+// New creates a z-base-32 codec with the given name.
+func New(name string) (cdc *codec.Codec) {
+
+	cdc = &codec.Codec{
+		Name:    name,
+		Charset: Alphabet,
+	}
+
+	cdc.Encoder = func(input []byte) (output string, err error) {
+
+		if len(input) < 1 {
+
+			err = proto.Error_ZERO_LENGTH
+			return
+		}
+
+		var symbols []byte
+		if symbols, err = bech32.ConvertBits(input, 8, 5, true); err != nil {
+			return
+		}
+
+		var sb strings.Builder
+		sb.Grow(len(symbols))
+		for _, s := range symbols {
+
+			sb.WriteByte(Alphabet[s])
+		}
+
+		return sb.String(), nil
+	}
+
+	cdc.Decoder = func(input string) (output []byte, err error) {
+
+		if len(input) < 1 {
+
+			err = proto.Error_ZERO_LENGTH
+			return
+		}
+
+		// The number of symbols must be one that encoding some number of
+		// bytes gives, which leaves fewer than 5 bits over.
+		if len(input)*5%8 >= 5 {
+
+			err = proto.Error_INVALID_LENGTH
+			return
+		}
+
+		symbols := make([]byte, len(input))
+		for i := range symbols {
+
+			v := values[input[i]]
+			if v < 0 {
+
+				err = proto.Error_INVALID_CHARACTER
+				return
+			}
+			symbols[i] = byte(v)
+		}
+
+		return bech32.ConvertBits(symbols, 5, 8, false)
+	}
+
+	return
+}
@@ -0,0 +1,96 @@
+package zbase32
+
+import (
+	"bytes"
+	"encoding/base32"
+	"errors"
+	"github.com/quanterall/kitchensink/pkg/proto"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestVectors(t *testing.T) {
+
+	for _, v := range []struct {
+		input   []byte
+		encoded string
+	}{
+		{[]byte{0x00}, "yy"},
+		{[]byte{0xf0, 0xbf, 0xc7}, "6n9hq"},
+		{[]byte{0xd4, 0x7a, 0x04}, "4t7ye"},
+		{[]byte("hello"), "pb1sa5dx"},
+	} {
+
+		encoded, err := Codec.Encode(v.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encoded != v.encoded {
+			t.Fatalf("%x encoded to '%s' expected '%s'",
+				v.input, encoded, v.encoded,
+			)
+		}
+
+		for _, variant := range []string{v.encoded, strings.ToUpper(v.encoded)} {
+
+			decoded, err := Codec.Decode(variant)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decoded, v.input) {
+				t.Fatalf("'%s' decoded to %x expected %x",
+					variant, decoded, v.input,
+				)
+			}
+		}
+	}
+
+	// The encoding of bytes is the same as the standard library base32 with
+	// the z-base-32 alphabet.
+	std := base32.NewEncoding(Alphabet).WithPadding(base32.NoPadding)
+	for l := 1; l <= 64; l++ {
+
+		input := make([]byte, l)
+		rand.Read(input)
+
+		encoded, err := Codec.Encode(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := std.EncodeToString(input); encoded != expected {
+			t.Fatalf("%x encoded to '%s' expected '%s'", input, encoded, expected)
+		}
+
+		decoded, err := Codec.Decode(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Fatalf("'%s' decoded to %x expected %x", encoded, decoded, input)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+
+	for _, c := range []struct {
+		input string
+		err   error
+	}{
+		{"", proto.Error_ZERO_LENGTH},
+		{"y", proto.Error_INVALID_LENGTH},
+		{"yyy", proto.Error_INVALID_LENGTH},
+		{"yv", proto.Error_INVALID_CHARACTER},
+		{"yb", proto.Error_INVALID_PADDING},
+	} {
+
+		if _, err := Codec.Decode(c.input); !errors.Is(err, c.err) {
+			t.Fatalf("'%s' gave error %v expected %v", c.input, err, c.err)
+		}
+	}
+
+	if _, err := Codec.Encode(nil); !errors.Is(err, proto.Error_ZERO_LENGTH) {
+		t.Fatalf("got error %v", err)
+	}
+}